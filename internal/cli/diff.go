@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var (
+	diffOutput   string
+	diffExitCode bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <before.json> [after.json]",
+	Short: "Compare port states at two points in time",
+	Long: `Compare snapshots saved by check --save, reporting ports that were opened, closed or
+taken over by another process
+With a single snapshot, compare it with the live state, filtered as the snapshot was
+--exit-code exits with status 9 if there are differences, for checks in test or deployment scripts`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", core.OutputTable, "output format: table|json")
+	diffCmd.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with status 9 if there are differences")
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	var afterPath string
+	if len(args) > 1 {
+		afterPath = args[1]
+	}
+
+	opts := core.DiffOptions{
+		Output:   diffOutput,
+		ExitCode: diffExitCode,
+	}
+
+	if err := core.DiffPorts(cmd.Context(), args[0], afterPath, opts); err != nil {
+		exitWithError(cmd, "failed to compare port states", err)
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var doctorOutput string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the port discovery backends and the environment",
+	Long: `Check which port discovery backends work on this host, with their versions and timings,
+and show the backend check actually uses
+On Linux it checks ss, netstat, lsof, /proc, netlink and ps in turn, and reports:
+- whether /proc is mounted with hidepid
+- whether it runs in WSL, a container or a user namespace
+- the capabilities currently held
+Exits with status 7 if no socket collector works`,
+	Args: cobra.NoArgs,
+	Run:  runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVarP(&doctorOutput, "output", "o", core.OutputTable, "output format: table|json")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	if err := core.RunDoctor(cmd.Context(), core.DoctorOptions{Output: doctorOutput}); err != nil {
+		exitWithError(cmd, "environment check failed", err)
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <port>",
+	Short: "Diagnose why a port is unavailable",
+	Long: `Diagnose why a port cannot be bound (address already in use) and suggest remediations
+On Linux it also checks:
+- listeners in other network namespaces (containers)
+- connections in TIME_WAIT
+- ip_local_reserved_ports and the ephemeral port range
+- privileged ports and CAP_NET_BIND_SERVICE
+- NFS/RPC sockets held by the kernel`,
+	Args: cobra.ExactArgs(1),
+	Run:  runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) {
+	if err := core.ExplainPort(cmd.Context(), args[0]); err != nil {
+		exitWithError(cmd, "failed to diagnose port", err)
+	}
+}
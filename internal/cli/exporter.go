@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var exporterListen string
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Start the Prometheus metrics exporter",
+	Long: `Serve Prometheus metrics on /metrics, rescanning the sockets on every scrape
+  portreleasor_listening_sockets      listening sockets by port, protocol and process
+  portreleasor_connections            non-listening sockets by protocol and state
+  portreleasor_scrape_duration_seconds  duration of the scan
+  portreleasor_scrape_success         whether the last scan succeeded
+  portreleasor_scrape_errors_total    number of failed scans
+
+The serve command provides /metrics as well`,
+	Args: cobra.NoArgs,
+	Run:  runExporter,
+}
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+
+	exporterCmd.Flags().StringVar(&exporterListen, "listen", "127.0.0.1:9877", "address to listen on")
+}
+
+func runExporter(cmd *cobra.Command, args []string) {
+	opts := core.ExporterOptions{
+		Listen: exporterListen,
+	}
+
+	if err := core.ExportMetrics(cmd.Context(), opts); err != nil {
+		exitWithError(cmd, "failed to start the metrics exporter", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var (
+	guardPolicy string
+	guardOnce   bool
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard --policy policy.yaml",
+	Short: "Watch listening ports against an allow-list policy",
+	Long: `Continuously watch the listening ports, logging listeners missing from the allow list,
+notifying a webhook, and optionally releasing them after a grace period (release: true).
+Protected system processes are never released
+
+Example policy:
+  interval: 5s
+  grace_period: 30s
+  release: false
+  webhook: https://hooks.example.com/ports
+  allow:
+    - port: 22
+      process: sshd
+    - port: 8000-8100
+      address: 127.0.0.1
+      process: "python*"
+      user: deploy
+
+--once checks only once and exits with status 10 on violations`,
+	Args: cobra.NoArgs,
+	Run:  runGuard,
+}
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+
+	guardCmd.Flags().StringVar(&guardPolicy, "policy", "", "policy file (YAML)")
+	guardCmd.Flags().BoolVar(&guardOnce, "once", false, "check only once and exit with status 10 on violations")
+	guardCmd.MarkFlagRequired("policy")
+}
+
+func runGuard(cmd *cobra.Command, args []string) {
+	opts := core.GuardOptions{
+		PolicyPath: guardPolicy,
+		Once:       guardOnce,
+	}
+
+	if err := core.Guard(cmd.Context(), opts); err != nil {
+		exitWithError(cmd, "failed to guard ports", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"portreleasor/internal/i18n"
+)
+
+var language string
+
+// usageHeadings cobra 默认用法模板中需要翻译的文本
+var usageHeadings = []string{
+	"Usage:",
+	"Aliases:",
+	"Examples:",
+	"Available Commands:",
+	"Additional Commands:",
+	"Global Flags:",
+	"Flags:",
+	"Additional help topics:",
+	`Use "{{.CommandPath}} [command] --help" for more information about a command.`,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&language, "lang", "", "language of the messages: en|zh-CN (default from LC_ALL, LC_MESSAGES or LANG)")
+}
+
+// setupLanguage 根据 --lang 或环境变量选择语言，并翻译命令的帮助文本。
+// 帮助在解析参数时就会输出，因此在执行命令前从参数中预先读取 --lang
+func setupLanguage(args []string) error {
+	lang, err := i18n.Detect(langArg(args))
+	if err != nil {
+		return err
+	}
+	i18n.SetLanguage(lang)
+	if lang == i18n.English {
+		return nil
+	}
+
+	// 提前创建 cobra 默认的 help、completion 命令和 --help 参数，以便翻译其说明
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	localizeCommand(rootCmd)
+
+	replacements := make([]string, 0, 2*len(usageHeadings))
+	for _, heading := range usageHeadings {
+		replacements = append(replacements, heading, i18n.T(heading))
+	}
+	rootCmd.SetUsageTemplate(strings.NewReplacer(replacements...).Replace(rootCmd.UsageTemplate()))
+	return nil
+}
+
+// langArg 返回命令行中 --lang 的值，未指定时为空
+func langArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if value, ok := strings.CutPrefix(arg, "--lang="); ok {
+			return value
+		}
+		if arg == "--lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// localizeCommand 翻译命令及其子命令的简介、说明和参数说明
+func localizeCommand(cmd *cobra.Command) {
+	cmd.InitDefaultHelpFlag()
+	if help := cmd.Flags().Lookup("help"); help != nil {
+		help.Usage = i18n.Sprintf("help for %s", cmd.Name())
+	}
+
+	cmd.Short = i18n.T(cmd.Short)
+	cmd.Long = i18n.T(cmd.Long)
+	localizeFlag := func(flag *pflag.Flag) {
+		flag.Usage = i18n.T(flag.Usage)
+	}
+	cmd.Flags().VisitAll(localizeFlag)
+	cmd.PersistentFlags().VisitAll(localizeFlag)
+
+	for _, child := range cmd.Commands() {
+		localizeCommand(child)
+	}
+}
@@ -1,70 +1,262 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/spf13/cobra"
 	"portreleasor/internal/core"
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
 )
 
 var (
 	releasePorts    []string
 	forceRelease    bool
+	nonInteractive  bool
+	releaseNames    []string
+	releasePIDs     []int
+	releaseExes     []string
+	releaseStdin    bool
+	releaseStdinPID bool
 	checkPorts      []string
 	verboseCheck    bool
 	wildcardCheck   bool
+	checkOutput     string
+	checkGroupBy    string
+	checkSort       string
+	checkReverse    bool
+	checkSave       string
+	checkProcesses  []string
+	checkUsers      []string
+	backendTimeout  time.Duration
+	backendTimeouts map[string]string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "portreleasor",
-	Short: "跨平台端口释放工具",
-	Long: `PortReleasor 是一个跨平台的端口管理工具，
-可以检查端口占用情况并释放指定端口`,
+	Short: "Cross-platform port releasing tool",
+	Long: `PortReleasor is a cross-platform port management tool
+that checks port usage and releases the specified ports
+
+The system tools it calls (ss, netstat, lsof, ps, tasklist, wmic, etc.) run for at most
+10 seconds by default; adjust this with --timeout, or per tool with --backend-timeout lsof=30s.
+Timeouts and switches to a fallback tool are reported on standard error; press Ctrl-C to cancel at any time
+
+Messages are shown in the language selected by --lang, otherwise by the LC_ALL,
+LC_MESSAGES or LANG environment variables (en, zh-CN); JSON output is always in English
+
+Exit codes: 0 success, 1 other error, 2 invalid argument, port or pattern, 3 no match, 4 permission denied,
+5 partial failure, 6 protected process, 7 no backend available, 8 backend timeout,
+9 differences found (diff --exit-code), 10 policy violations (guard --once), 130 cancelled`,
+	PersistentPreRunE: configureBackends,
+	// 错误由 main 按所选语言输出
+	SilenceErrors: true,
 }
 
 var releaseCmd = &cobra.Command{
 	Use:   "release [ports...]",
-	Short: "释放指定端口",
-	Long: `释放被占用的端口，支持：
-- 单个端口: 8080
-- 多个端口: 8080 8081 8082
-- 端口范围: 8080-8090`,
+	Short: "Release the specified ports",
+	Long: `Release ports in use, given as:
+- a single port: 8080
+- several ports: 8080 8081 8082
+- a port range: 8080-8090
+- qualified by protocol, local address or interface, sparing the other sockets on the port:
+  53/udp, 127.0.0.1:8080, [::1]:9000, docker0:5432/tcp
+
+All ports held by processes can be released as well:
+--process java           by process name
+--pid 1234               by process ID
+--exe /opt/app/bin/server by executable path
+
+--stdin reads the ports to release from standard input, as the JSON output of check
+(one record per line is accepted too) or a list of ports; --stdin-pids reads a list of PIDs.
+Every record is checked against the live state first, and entries whose port is free
+or now held by another process are skipped:
+  portreleasor check --user deploy --process node -o json | jq '...' | portreleasor release --stdin
+The confirmation is read from the terminal, use -f when there is none
+
+By default the processes to kill are selected one by one (e.g. 1,3-4; a all, n none, ? details)
+-f kills every process without confirmation
+--non-interactive asks a single y/N confirmation, suitable for input piped in from scripts
+--sudo runs with administrator privileges through sudo/doas/pkexec; without it, processes
+that could not be killed for lack of privileges are offered for an elevated retry in a
+terminal, naming the missing privileges (e.g. CAP_KILL)`,
 	Run: runRelease,
 }
 
 var checkCmd = &cobra.Command{
 	Use:   "check [pattern]",
-	Short: "检查端口占用情况",
-	Long: `检查端口占用情况，显示端口、进程ID、协议和程序信息
--v 显示程序的绝对路径
--w 通配符模式匹配`,
+	Short: "Check port usage",
+	Long: `Check port usage, showing the port, process ID, protocol and program
+-v shows the absolute path, full command line, working directory and start time of the program
+-w matches wildcard patterns
+-o output format: table (default) or json
+--group-by groups by process|user|container|protocol
+--sort sorts by port|pid|process|user|state|start-time, --reverse reverses the order
+Sorted by port, protocol and address by default
+--save saves the result as a snapshot file to compare with the diff command
+--process and --user keep only the ports of processes with these names or owners
+--sudo runs with administrator privileges through sudo/doas/pkexec to show processes of other users;
+without it, rows left incomplete for lack of privileges are summarized at the end,
+naming the missing privileges, with an offer to re-run elevated`,
 	Run: runCheck,
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	if err := setupLanguage(os.Args[1:]); err != nil {
+		return core.WithKind(core.ErrInvalidPattern, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 第一次 Ctrl-C 取消当前操作，之后恢复默认处理，再次 Ctrl-C 直接退出
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// configureBackends 将后端命令的超时设置和警告输出附加到命令上下文
+func configureBackends(cmd *cobra.Command, args []string) error {
+	timeouts := platform.Timeouts{Default: backendTimeout, Backends: make(map[string]time.Duration)}
+	for name, value := range backendTimeouts {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return core.WithKind(core.ErrInvalidPattern, i18n.Errorf("invalid timeout %s=%s", name, value))
+		}
+		timeouts.Backends[name] = timeout
+	}
+	if backendTimeout <= 0 {
+		return core.WithKind(core.ErrInvalidPattern, i18n.Errorf("invalid timeout %s", backendTimeout))
+	}
+
+	ctx := platform.WithTimeouts(cmd.Context(), timeouts)
+	ctx = platform.WithReporter(ctx, func(message string) {
+		i18n.Fprintf(os.Stderr, "Warning: %s\n", message)
+	})
+	cmd.SetContext(ctx)
+	return nil
+}
+
+// exitIfCancelled 操作被 Ctrl-C 取消时以 130 退出，不再显示由取消引起的错误
+func exitIfCancelled(cmd *cobra.Command) {
+	if cmd.Context().Err() != nil {
+		i18n.Fprintf(os.Stderr, "Operation cancelled\n")
+		os.Exit(130)
+	}
+}
+
+// exitWithError 以错误类型对应的退出码退出（见 core.ExitCode），action 为待翻译的英文消息；
+// 没有匹配项、存在差异或违反策略时命令已输出结果，只设置退出码
+func exitWithError(cmd *cobra.Command, action string, err error) {
+	exitIfCancelled(cmd)
+	if !errors.Is(err, core.ErrNoMatch) && !errors.Is(err, core.ErrDifferences) && !errors.Is(err, core.ErrPolicyViolations) {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", i18n.T(action), i18n.Localize(err))
+	}
+	os.Exit(core.ExitCode(err))
 }
 
 func init() {
 	rootCmd.AddCommand(releaseCmd)
 	rootCmd.AddCommand(checkCmd)
 
+	rootCmd.PersistentFlags().DurationVar(&backendTimeout, "timeout", platform.DefaultTimeout, "timeout of each backend command")
+	rootCmd.PersistentFlags().StringToStringVar(&backendTimeouts, "backend-timeout", nil, "timeout of individual backend commands, e.g. lsof=30s,ss=2s")
+	// 无法解析的参数值（如 --timeout abc）与无效的端口一样以退出码 2 退出
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return core.WithKind(core.ErrInvalidPattern, err)
+	})
+
 	// Release command flags
-	releaseCmd.Flags().BoolVarP(&forceRelease, "force", "f", false, "强制释放，无需确认")
-	releaseCmd.Args = cobra.MinimumNArgs(1)
+	releaseCmd.Flags().BoolVarP(&forceRelease, "force", "f", false, "release without confirmation")
+	releaseCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "non-interactive mode, with a single y/N confirmation")
+	releaseCmd.Flags().StringSliceVar(&releaseNames, "process", nil, "release all ports held by processes with this name")
+	releaseCmd.Flags().IntSliceVar(&releasePIDs, "pid", nil, "release all ports held by the process with this ID")
+	releaseCmd.Flags().StringSliceVar(&releaseExes, "exe", nil, "release all ports held by processes running this executable")
+	releaseCmd.Flags().BoolVar(&releaseStdin, "stdin", false, "read the ports to release from standard input: JSON records or a list of ports")
+	releaseCmd.Flags().BoolVar(&releaseStdinPID, "stdin-pids", false, "read a list of PIDs to release from standard input")
+	releaseCmd.Args = validateReleaseArgs
 
 	// Check command flags
-	checkCmd.Flags().BoolVarP(&verboseCheck, "verbose", "v", false, "显示程序的绝对路径")
-	checkCmd.Flags().BoolVarP(&wildcardCheck, "wildcard", "w", false, "通配符模式匹配")
+	checkCmd.Flags().BoolVarP(&verboseCheck, "verbose", "v", false, "show the absolute path, command line, working directory and start time of the program")
+	checkCmd.Flags().BoolVarP(&wildcardCheck, "wildcard", "w", false, "match wildcard patterns")
+	checkCmd.Flags().StringVarP(&checkOutput, "output", "o", core.OutputTable, "output format: table|json")
+	checkCmd.Flags().StringVar(&checkGroupBy, "group-by", "", "group by: process|user|container|protocol")
+	checkCmd.Flags().StringVar(&checkSort, "sort", core.SortByPort, "sort by: port|pid|process|user|state|start-time")
+	checkCmd.Flags().BoolVar(&checkReverse, "reverse", false, "reverse the sort order")
+	checkCmd.Flags().StringVar(&checkSave, "save", "", "save a snapshot to the file")
+	checkCmd.Flags().StringSliceVar(&checkProcesses, "process", nil, "only show ports held by processes with this name")
+	checkCmd.Flags().StringSliceVar(&checkUsers, "user", nil, "only show ports held by processes of this user")
+}
+
+// validateReleaseArgs 校验端口参数、进程选择参数和 --stdin/--stdin-pids 三选一
+func validateReleaseArgs(cmd *cobra.Command, args []string) error {
+	byProcess := len(releaseNames) > 0 || len(releasePIDs) > 0 || len(releaseExes) > 0
+	fromStdin := releaseStdin || releaseStdinPID
+	var err error
+	switch {
+	case releaseStdin && releaseStdinPID:
+		err = i18n.Errorf("--stdin cannot be combined with --stdin-pids")
+	case fromStdin && (byProcess || len(args) > 0):
+		err = i18n.Errorf("--stdin/--stdin-pids cannot be combined with ports or --process/--pid/--exe")
+	case fromStdin:
+	case byProcess && len(args) > 0:
+		err = i18n.Errorf("ports cannot be combined with --process/--pid/--exe")
+	case !byProcess && len(args) == 0:
+		err = i18n.Errorf("specify ports, processes with --process/--pid/--exe, or --stdin")
+	}
+	if err != nil {
+		return core.WithKind(core.ErrInvalidPattern, err)
+	}
+	return nil
 }
 
 func runRelease(cmd *cobra.Command, args []string) {
 	releasePorts = args
 
-	if err := core.ReleasePorts(releasePorts, forceRelease); err != nil {
-		fmt.Fprintf(os.Stderr, "释放端口失败: %v\n", err)
-		os.Exit(1)
+	opts := core.ReleaseOptions{
+		Force:          forceRelease,
+		NonInteractive: nonInteractive,
+		Elevate:        elevate,
+	}
+
+	var err error
+	if releaseStdin || releaseStdinPID {
+		// 标准输入用于读取端口，确认改从终端读取
+		if !forceRelease {
+			terminal, err := platform.OpenTerminal()
+			if err != nil {
+				exitWithError(cmd, "failed to release ports",
+					i18n.Errorf("no terminal to confirm the release of piped ports, use --force: %w", err))
+			}
+			defer terminal.Close()
+			opts.Prompt = terminal
+		}
+		list := core.StdinPorts
+		if releaseStdinPID {
+			list = core.StdinPIDs
+		}
+		err = core.ReleaseInput(cmd.Context(), os.Stdin, list, opts)
+	} else if len(releasePorts) > 0 {
+		err = core.ReleasePorts(cmd.Context(), releasePorts, opts)
+	} else {
+		err = core.ReleaseProcesses(cmd.Context(), core.ProcessFilter{
+			Names: releaseNames,
+			PIDs:  releasePIDs,
+			Exes:  releaseExes,
+		}, opts)
+	}
+
+	if err != nil {
+		exitWithError(cmd, "failed to release ports", err)
 	}
 }
 
@@ -73,8 +265,20 @@ func runCheck(cmd *cobra.Command, args []string) {
 		checkPorts = args
 	}
 
-	if err := core.CheckPorts(checkPorts, verboseCheck, wildcardCheck); err != nil {
-		fmt.Fprintf(os.Stderr, "检查端口失败: %v\n", err)
-		os.Exit(1)
+	opts := core.CheckOptions{
+		Verbose:   verboseCheck,
+		Wildcard:  wildcardCheck,
+		Output:    checkOutput,
+		GroupBy:   checkGroupBy,
+		Sort:      checkSort,
+		Reverse:   checkReverse,
+		Save:      checkSave,
+		Processes: checkProcesses,
+		Users:     checkUsers,
+		Elevate:   elevate,
+	}
+
+	if err := core.CheckPorts(cmd.Context(), checkPorts, opts); err != nil {
+		exitWithError(cmd, "failed to check ports", err)
 	}
 }
\ No newline at end of file
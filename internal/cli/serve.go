@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var (
+	serveListen   string
+	serveToken    string
+	serveInterval time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the HTTP API server",
+	Long: `Start a JSON REST API for dashboards, IDE plugins and orchestration scripts
+  GET  /ports          list ports, with the pattern, wildcard, sort, reverse and group_by query parameters
+  GET  /ports/{port}   look up a single port (port number or service name)
+  POST /release        release ports according to a plan, e.g. {"ports":["8080"],"dry_run":true},
+                       sent as application/json
+  GET  /events         Server-Sent Events stream of port changes
+
+Once --token or the PORTRELEASOR_TOKEN environment variable is set, every request must carry
+Authorization: Bearer <token>. Without a token only loopback addresses may be listened on,
+POST /release is disabled, and requests must name a loopback host, e.g. localhost`,
+	Args: cobra.NoArgs,
+	Run:  runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:9876", "address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "bearer token (default from PORTRELEASOR_TOKEN)")
+	serveCmd.Flags().DurationVar(&serveInterval, "interval", 2*time.Second, "scan interval of the event stream")
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("PORTRELEASOR_TOKEN")
+	}
+
+	opts := core.ServeOptions{
+		Listen:   serveListen,
+		Token:    token,
+		Interval: serveInterval,
+	}
+
+	if err := core.Serve(cmd.Context(), opts); err != nil {
+		exitWithError(cmd, "failed to start the API server", err)
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+)
+
+var useSudo bool
+
+// elevate 通过 sudo/doas/pkexec 以管理员权限重新运行本程序，成功启动后以其退出码退出；
+// args 为空时使用当前命令行参数，否则附加用户指定的全局参数（如 --lang、--timeout）
+func elevate(args []string) error {
+	tool, err := platform.FindEscalator()
+	if err != nil {
+		return err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return i18n.Errorf("cannot locate the running executable: %w", err)
+	}
+	if args == nil {
+		args = os.Args[1:]
+	} else {
+		args = append(args, persistentArgs()...)
+	}
+
+	i18n.Fprintf(os.Stderr, "Re-running with %s...\n", filepath.Base(tool))
+
+	// 不绑定命令上下文：Ctrl-C 会同时发送给子进程，由其自行退出
+	child := exec.Command(tool, append([]string{self}, args...)...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	err = child.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
+
+// persistentArgs 返回命令行上显式指定的全局参数，使重试命令与原命令的语言和超时一致
+func persistentArgs() []string {
+	var args []string
+	// 参数由子命令解析，只能通过 Changed 判断，Visit 只遍历根命令自身解析的参数
+	rootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		value := flag.Value.String()
+		if flag.Value.Type() == "stringToString" {
+			// String() 带有方括号，Set() 不接受
+			value = strings.Trim(value, "[]")
+		}
+		args = append(args, "--"+flag.Name+"="+value)
+	})
+	return args
+}
+
+// elevateIfRequested 指定 --sudo 且当前不是管理员时，在执行任何操作前提权重新运行
+func elevateIfRequested(cmd *cobra.Command, args []string) {
+	if !useSudo || platform.Elevated() {
+		return
+	}
+	if err := elevate(nil); err != nil {
+		i18n.Fprintf(os.Stderr, "failed to elevate privileges: %s\n", i18n.Localize(err))
+		os.Exit(1)
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{checkCmd, releaseCmd} {
+		cmd.Flags().BoolVar(&useSudo, "sudo", false, "run with administrator privileges through sudo/doas/pkexec")
+		cmd.PreRun = elevateIfRequested
+	}
+}
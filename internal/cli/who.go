@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+	"portreleasor/internal/core"
+)
+
+var whoCmd = &cobra.Command{
+	Use:   "who <pid|name>",
+	Short: "Show all ports held by a process",
+	Long: `Look up all sockets (listening and established) held by a process ID or name,
+showing the protocol, local address, remote address and state, including the sockets
+of its child processes`,
+	Args: cobra.ExactArgs(1),
+	Run:  runWho,
+}
+
+func init() {
+	rootCmd.AddCommand(whoCmd)
+}
+
+func runWho(cmd *cobra.Command, args []string) {
+	if err := core.WhoProcess(cmd.Context(), args[0]); err != nil {
+		exitWithError(cmd, "failed to look up process ports", err)
+	}
+}
@@ -1,133 +1,348 @@
 package core
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"portreleasor/internal/i18n"
 	"portreleasor/internal/platform"
 	"portreleasor/internal/types"
 	"portreleasor/internal/utils"
 )
 
+// CheckOptions controls how CheckPorts filters and displays port information
+type CheckOptions struct {
+	// Verbose shows the executable path, command line, working directory and start time
+	Verbose bool
+	// Wildcard matches ports containing the pattern instead of equal to it
+	Wildcard bool
+	// Output is the output format, OutputTable or OutputJSON
+	Output string
+	// GroupBy groups the ports by process, user, container or protocol, empty for a flat list
+	GroupBy string
+	// Sort is the sort key, empty for the default port/protocol/address order
+	Sort string
+	// Reverse reverses the sort order
+	Reverse bool
+	// Save writes the matching ports to a snapshot file for later diffing
+	Save string
+	// Targets keeps only the ports matching one of these targets, which unlike the
+	// patterns may be ranges or qualified by protocol and address
+	Targets []utils.PortTarget
+	// Processes keeps only the ports of processes with one of these names; with PIDs
+	// and Exes, a port is kept if its process matches any of them
+	Processes []string
+	// PIDs keeps only the ports of processes with one of these IDs
+	PIDs []int
+	// Exes keeps only the ports of processes with one of these executable paths
+	Exes []string
+	// Users keeps only the ports of processes owned by one of these users
+	Users []string
+	// Elevate, if set, is offered when rows are incomplete for lack of privileges
+	Elevate ElevateFunc
+}
+
 // CheckPorts checks and displays port usage information
-func CheckPorts(patterns []string, verbose bool, wildcard bool) error {
-	manager := platform.GetPlatformManager()
-	if manager == nil {
-		return fmt.Errorf("unsupported platform")
+func CheckPorts(ctx context.Context, patterns []string, opts CheckOptions) error {
+	if err := validateOutput(opts.Output); err != nil {
+		return err
 	}
 
-	connections, err := manager.GetPortConnections()
+	// 结构化输出和快照始终包含完整的进程信息
+	enrich := opts.Verbose || opts.Output == OutputJSON || opts.Save != ""
+
+	filtered, err := QueryPorts(ctx, patterns, opts, enrich)
 	if err != nil {
-		return fmt.Errorf("failed to get port connections: %v", err)
+		return err
 	}
 
-	var filtered []types.PortInfo
+	if opts.Save != "" {
+		manager := platform.GetPlatformManager()
+		saved, err := saveSnapshot(ctx, manager, opts.Save, SnapshotFilter{
+			Patterns:  patterns,
+			Wildcard:  opts.Wildcard,
+			Processes: opts.Processes,
+			Users:     opts.Users,
+		})
+		if err != nil {
+			return err
+		}
+		// 提示信息写到 stderr，避免污染 JSON 输出
+		i18n.Fprintf(os.Stderr, "Snapshot of %d port(s) saved to %s\n", saved, opts.Save)
+	}
 
-	if len(patterns) == 0 {
-		filtered = connections
-	} else {
-		for _, conn := range connections {
-			for _, pattern := range patterns {
-				if wildcard {
-					if utils.MatchWildcard(conn.Port, pattern) {
-						filtered = append(filtered, conn)
-						break
-					}
-				} else {
-					port, err := strconv.Atoi(pattern)
-					if err == nil && conn.Port == port {
-						filtered = append(filtered, conn)
-						break
-					}
-				}
+	if len(filtered) == 0 {
+		if opts.Output == OutputJSON {
+			if err := printJSON([]types.PortInfo{}); err != nil {
+				return err
 			}
+			return errorf(ErrNoMatch, "no matching ports found")
 		}
+		i18n.Println("No matching ports found")
+		return errorf(ErrNoMatch, "no matching ports found")
 	}
 
-	if len(filtered) == 0 {
-		fmt.Println("No matching ports found")
-		return nil
+	if opts.GroupBy != "" {
+		groups := groupPorts(filtered, opts.GroupBy)
+		if opts.Output == OutputJSON {
+			return printJSON(groupedOutput{GroupBy: opts.GroupBy, Total: len(filtered), Groups: groups})
+		}
+		printGroupTree(groups, opts.GroupBy)
+		i18n.Printf("\nShowing %d unique port(s) in %d group(s)\n", len(filtered), len(groups))
+	} else {
+		if opts.Output == OutputJSON {
+			return printJSON(filtered)
+		}
+		printPortTable(filtered, opts.Verbose)
+		i18n.Printf("\nShowing %d unique port(s)\n", len(filtered))
 	}
 
-	if verbose {
-		for _, conn := range filtered {
-			if conn.ProcessPath == "" {
-				path, err := manager.GetProcessPath(conn.PID)
-				if err == nil {
-					conn.ProcessPath = path
-				}
-			}
+	for _, conn := range filtered {
+		if conn.ServiceMismatch {
+			i18n.Printf("Warning: %d/%s (%s) is served by %s (PID %d), expected %s\n",
+				conn.Port, conn.Protocol, conn.Service, conn.ProcessName, conn.PID,
+				strings.Join(utils.ExpectedDaemons(conn.Port), "/"))
 		}
 	}
+	if printIncompleteSummary(filtered) > 0 {
+		offerElevation(ctx, opts.Elevate, i18n.T("Re-run with elevated privileges to show them?"), nil)
+	}
 
-	// 使用固定宽度格式化输出
-	portProtocolWidth := 15
-	pidWidth := 8
-	processWidth := 20
-	pathWidth := 40
+	return nil
+}
 
-	// 动态调整列宽以适应实际数据
-	for _, conn := range filtered {
-		portProtocol := fmt.Sprintf("%d/%s", conn.Port, conn.Protocol)
-		if len(portProtocol) > portProtocolWidth {
-			portProtocolWidth = len(portProtocol)
+// QueryPorts returns the ports matching the patterns, filtered and sorted as check does.
+// enrich fills in the command line, working directory, start time, user and container
+// of each process, which is also done whenever the grouping or sort key needs them.
+func QueryPorts(ctx context.Context, patterns []string, opts CheckOptions, enrich bool) ([]types.PortInfo, error) {
+	if err := validateGroupBy(opts.GroupBy); err != nil {
+		return nil, err
+	}
+	if err := validateSort(opts.Sort); err != nil {
+		return nil, err
+	}
+	for _, pattern := range patterns {
+		if err := validatePattern(pattern, opts.Wildcard); err != nil {
+			return nil, err
 		}
-		pidStr := fmt.Sprintf("%d", conn.PID)
-		if len(pidStr) > pidWidth {
-			pidWidth = len(pidStr)
+	}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return nil, errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	processes := ProcessFilter{Names: opts.Processes, PIDs: opts.PIDs, Exes: opts.Exes}
+	var filtered []types.PortInfo
+	if len(opts.Targets) > 0 || !processes.IsEmpty() || len(opts.Users) > 0 {
+		// 按目标、进程或用户筛选时要检查每个监听套接字，GetListeningSockets 每个端口和
+		// 协议只保留一个，会漏掉其他地址上的监听和共享端口的进程（如 nginx worker）
+		listening, err := listeningSockets(ctx, manager)
+		if err != nil {
+			return nil, err
 		}
-		if len(conn.ProcessName) > processWidth {
-			processWidth = len(conn.ProcessName)
+		filtered = matchPatterns(listening, patterns, opts.Wildcard)
+		if len(opts.Targets) > 0 {
+			filtered = matchTargets(filtered, opts.Targets)
 		}
-		if verbose && len(conn.ProcessPath) > pathWidth {
-			pathWidth = len(conn.ProcessPath)
+	} else {
+		// 先列出套接字并按端口筛选，只解析匹配端口的进程信息
+		connections, err := manager.GetListeningSockets(ctx)
+		if err != nil {
+			return nil, collectError(err)
 		}
+		filtered = matchPatterns(connections, patterns, opts.Wildcard)
+		manager.ResolveProcesses(ctx, filtered)
+	}
+	if !processes.IsEmpty() {
+		filtered = matchProcesses(filtered, processes)
+	}
+	annotateServices(filtered)
+
+	if enrich || opts.GroupBy == GroupByUser || opts.GroupBy == GroupByContainer ||
+		opts.Sort == SortByUser || opts.Sort == SortByStartTime || len(opts.Users) > 0 {
+		enrichProcessDetails(ctx, manager, filtered)
+	}
+	if len(opts.Users) > 0 {
+		filtered = matchUsers(filtered, opts.Users)
 	}
 
-	// 添加适当的间距
-	portProtocolWidth += 2
-	pidWidth += 2
-	processWidth += 2
-	pathWidth += 2
+	sortPorts(filtered, opts.Sort, opts.Reverse)
 
-	// 打印表头
-	if verbose {
-		header := fmt.Sprintf("%-*s %-*s %-*s %s",
-			portProtocolWidth, "PORT/PROTOCOL",
-			pidWidth, "PID",
-			processWidth, "PROCESS",
-			"PATH")
-		fmt.Println(header)
-		fmt.Println(strings.Repeat("-", portProtocolWidth+pidWidth+processWidth+pathWidth+3))
-	} else {
-		header := fmt.Sprintf("%-*s %-*s %s",
-			portProtocolWidth, "PORT/PROTOCOL",
-			pidWidth, "PID",
-			"PROCESS")
-		fmt.Println(header)
-		fmt.Println(strings.Repeat("-", portProtocolWidth+pidWidth+processWidth+2))
+	return filtered, nil
+}
+
+// matchPatterns returns the port records matching any of the patterns, all of them
+// if there is none
+func matchPatterns(connections []types.PortInfo, patterns []string, wildcard bool) []types.PortInfo {
+	if len(patterns) == 0 {
+		return connections
 	}
 
-	// 打印数据行
-	for _, conn := range filtered {
-		if verbose {
-			line := fmt.Sprintf("%-*s %-*d %-*s %s",
-				portProtocolWidth, fmt.Sprintf("%d/%s", conn.Port, conn.Protocol),
-				pidWidth, conn.PID,
-				processWidth, conn.ProcessName,
-				conn.ProcessPath)
-			fmt.Println(line)
-		} else {
-			line := fmt.Sprintf("%-*s %-*d %s",
-				portProtocolWidth, fmt.Sprintf("%d/%s", conn.Port, conn.Protocol),
-				pidWidth, conn.PID,
-				conn.ProcessName)
-			fmt.Println(line)
+	var filtered []types.PortInfo
+	for _, conn := range connections {
+		for _, pattern := range patterns {
+			if wildcard {
+				if utils.MatchWildcard(conn.Port, pattern) {
+					filtered = append(filtered, conn)
+					break
+				}
+			} else if matchPortPattern(conn.Port, pattern) {
+				filtered = append(filtered, conn)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// matchUsers returns the port records of processes owned by any of the users, ignoring
+// case and the Windows domain, e.g. "alice" matches "CORP\alice"
+func matchUsers(connections []types.PortInfo, users []string) []types.PortInfo {
+	var matched []types.PortInfo
+	for _, conn := range connections {
+		name := conn.User[strings.LastIndex(conn.User, `\`)+1:]
+		for _, user := range users {
+			if conn.User != "" && (strings.EqualFold(conn.User, user) || strings.EqualFold(name, user)) {
+				matched = append(matched, conn)
+				break
+			}
 		}
 	}
+	return matched
+}
 
-	fmt.Printf("\nShowing %d unique port(s)\n", len(filtered))
+// annotateServices fills in the registered service name of each port and flags
+// well-known ports served by an unexpected daemon
+func annotateServices(connections []types.PortInfo) {
+	for i := range connections {
+		conn := &connections[i]
+		conn.Service = utils.LookupService(conn.Port, conn.Protocol)
+		conn.ServiceMismatch = conn.PID > 0 && utils.IsUnexpectedDaemon(conn.Port, conn.ProcessName)
+	}
+}
 
-	return nil
+// validatePattern checks that a pattern is a port number or service name, or a string
+// of digits in wildcard mode
+func validatePattern(pattern string, wildcard bool) error {
+	if wildcard {
+		if pattern == "" || strings.Trim(pattern, "0123456789") != "" {
+			return errorf(ErrInvalidPattern, "invalid wildcard pattern %q: only digits are allowed", pattern)
+		}
+		return nil
+	}
+	if _, err := strconv.Atoi(pattern); err == nil || len(utils.LookupServicePorts(pattern)) > 0 {
+		return nil
+	}
+	return errorf(ErrInvalidPattern, "invalid pattern %q: not a port number or known service name", pattern)
+}
+
+// matchPortPattern reports whether a port matches a port number or service name pattern
+func matchPortPattern(port int, pattern string) bool {
+	if p, err := strconv.Atoi(pattern); err == nil {
+		return port == p
+	}
+	for _, p := range utils.LookupServicePorts(pattern) {
+		if port == p {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichProcessDetails fills in the executable path, command line, working directory,
+// start time, user and container of each port's process, querying the processes
+// concurrently, and records why the fields that failed could not be resolved
+func enrichProcessDetails(ctx context.Context, manager platform.PlatformManager, connections []types.PortInfo) {
+	type details struct {
+		path, cmdline, cwd string
+		user, container    string
+		startTime          *time.Time
+		status             map[string]types.FieldStatus
+	}
+
+	var pids []int
+	index := make(map[int]int)
+	for _, conn := range connections {
+		if _, exists := index[conn.PID]; conn.PID > 0 && !exists {
+			index[conn.PID] = len(pids)
+			pids = append(pids, conn.PID)
+		}
+	}
+
+	results := make([]details, len(pids))
+	utils.ForEach(len(pids), func(i int) {
+		pid, d := pids[i], &results[i]
+		d.status = make(map[string]types.FieldStatus)
+
+		// 失败原因只查询一次，同一进程的多个字段通常因同一原因失败
+		var processStatus types.FieldStatus
+		record := func(field string, err error) {
+			switch {
+			case err == nil:
+				d.status[field] = types.StatusOK
+			case errors.Is(err, platform.ErrUnsupported):
+				d.status[field] = types.StatusUnsupported
+			default:
+				if processStatus == "" {
+					processStatus = manager.ProcessStatus(ctx, pid)
+				}
+				d.status[field] = processStatus
+			}
+		}
+
+		var err error
+		d.path, err = manager.GetProcessPath(ctx, pid)
+		record(types.FieldProcessPath, err)
+		d.cmdline, err = manager.GetProcessCommandLine(ctx, pid)
+		record(types.FieldCommandLine, err)
+		d.cwd, err = manager.GetProcessCwd(ctx, pid)
+		record(types.FieldCwd, err)
+		if startTime, err := manager.GetProcessStartTime(ctx, pid); err == nil {
+			d.startTime = &startTime
+			record(types.FieldStartTime, nil)
+		} else {
+			record(types.FieldStartTime, err)
+		}
+		d.user, err = manager.GetProcessUser(ctx, pid)
+		record(types.FieldUser, err)
+		d.container, err = manager.GetProcessContainer(ctx, pid)
+		record(types.FieldContainer, err)
+	})
+
+	for i := range connections {
+		conn := &connections[i]
+		if conn.PID <= 0 {
+			continue
+		}
+
+		d := results[index[conn.PID]]
+		if conn.ProcessPath == "" {
+			conn.ProcessPath = d.path
+			conn.SetStatus(types.FieldProcessPath, d.status[types.FieldProcessPath])
+		}
+		conn.CommandLine = d.cmdline
+		conn.Cwd = d.cwd
+		conn.StartTime = d.startTime
+		conn.User = d.user
+		conn.Container = d.container
+		for _, field := range []string{types.FieldCommandLine, types.FieldCwd, types.FieldStartTime, types.FieldUser, types.FieldContainer} {
+			conn.SetStatus(field, d.status[field])
+		}
+	}
+}
+
+// serviceLabel returns the service column text, marking unexpected daemons with "!"
+func serviceLabel(conn types.PortInfo) string {
+	if conn.Service == "" {
+		return "-"
+	}
+	if conn.ServiceMismatch {
+		return conn.Service + "!"
+	}
+	return conn.Service
 }
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/types"
+)
+
+func TestCheckPorts(t *testing.T) {
+	wantTotal := map[string]int{
+		"linux-ss":            6,
+		"linux-netstat":       7,
+		"darwin-lsof":         5,
+		"windows-netstat-ano": 5,
+	}
+
+	for _, host := range fakeHosts {
+		t.Run(host.name, func(t *testing.T) {
+			host.install(t)
+
+			var all []types.PortInfo
+			output := captureStdout(t, func() {
+				if err := CheckPorts(context.Background(), nil, CheckOptions{Output: OutputJSON}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if err := json.Unmarshal([]byte(output), &all); err != nil {
+				t.Fatalf("invalid JSON output: %v\n%s", err, output)
+			}
+			if len(all) != wantTotal[host.name] {
+				t.Errorf("got %d ports, want %d:\n%s", len(all), wantTotal[host.name], output)
+			}
+			for i := 1; i < len(all); i++ {
+				if all[i].Less(all[i-1]) {
+					t.Errorf("ports not sorted: %s before %s", all[i-1], all[i])
+				}
+			}
+
+			var matched []types.PortInfo
+			output = captureStdout(t, func() {
+				if err := CheckPorts(context.Background(), []string{"8080"}, CheckOptions{Output: OutputJSON}); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if err := json.Unmarshal([]byte(output), &matched); err != nil {
+				t.Fatalf("invalid JSON output: %v\n%s", err, output)
+			}
+			if len(matched) != 1 {
+				t.Fatalf("got %d ports for 8080, want 1:\n%s", len(matched), output)
+			}
+			if got := matched[0]; got.PID != 4242 || got.State != "LISTENING" || got.User != "alice" {
+				t.Errorf("8080 = PID %d state %q user %q, want PID 4242 LISTENING alice", got.PID, got.State, got.User)
+			}
+		})
+	}
+}
+
+func TestCheckPortsNoMatch(t *testing.T) {
+	fakeHosts[0].install(t)
+
+	output := captureStdout(t, func() {
+		if err := CheckPorts(context.Background(), []string{"9"}, CheckOptions{Output: OutputJSON}); !errors.Is(err, ErrNoMatch) {
+			t.Errorf("CheckPorts returned %v, want ErrNoMatch", err)
+		}
+	})
+	if output != "[]\n" {
+		t.Errorf("got %q, want an empty JSON array", output)
+	}
+}
+
+func TestCheckPortsInvalidPattern(t *testing.T) {
+	fakeHosts[0].install(t)
+
+	for _, tt := range []struct {
+		pattern  string
+		wildcard bool
+	}{
+		{"no-such-service", false},
+		{"80x", true},
+	} {
+		err := CheckPorts(context.Background(), []string{tt.pattern}, CheckOptions{Wildcard: tt.wildcard})
+		if ExitCode(err) != ExitInvalidPattern {
+			t.Errorf("pattern %q: got %v (exit code %d), want exit code %d", tt.pattern, err, ExitCode(err), ExitInvalidPattern)
+		}
+	}
+}
+
+func TestCheckPortsInvalidOptions(t *testing.T) {
+	fakeHosts[0].install(t)
+
+	for _, opts := range []CheckOptions{
+		{Output: "xml"},
+		{GroupBy: "color"},
+		{Sort: "size"},
+	} {
+		if err := CheckPorts(context.Background(), nil, opts); ExitCode(err) != ExitInvalidPattern {
+			t.Errorf("CheckPorts(context.Background(), %+v) = %v (exit code %d), want exit code %d", opts, err, ExitCode(err), ExitInvalidPattern)
+		}
+	}
+}
+
+func TestCheckPortsCancelled(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Delay = time.Minute
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	captureStdout(t, func() {
+		if err := CheckPorts(ctx, nil, CheckOptions{}); err == nil {
+			t.Error("CheckPorts succeeded, want an error once cancelled")
+		}
+	})
+}
+
+func TestCheckPortsStatus(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Unprivileged = true
+	fake.Denied = map[int]bool{1001: true}
+
+	var all []types.PortInfo
+	output := captureStdout(t, func() {
+		if err := CheckPorts(context.Background(), nil, CheckOptions{Output: OutputJSON}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := json.Unmarshal([]byte(output), &all); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, output)
+	}
+
+	want := map[int]map[string]types.FieldStatus{
+		// 非root时看不到所有者的端口
+		2049: {types.FieldPID: types.StatusPermissionDenied, types.FieldProcessPath: types.StatusPermissionDenied},
+		// 其他用户的进程：进程名来自 ss，路径和用户无法读取
+		22: {types.FieldPID: types.StatusOK, types.FieldProcessName: types.StatusOK,
+			types.FieldProcessPath: types.StatusPermissionDenied, types.FieldUser: types.StatusPermissionDenied},
+		8080: {types.FieldPID: types.StatusOK, types.FieldUser: types.StatusOK, types.FieldProcessPath: types.StatusUnsupported},
+	}
+	for _, info := range all {
+		for field, status := range want[info.Port] {
+			if got := info.StatusOf(field); got != status {
+				t.Errorf("%s: status of %s = %q, want %q", info, field, got, status)
+			}
+		}
+	}
+
+	table := captureStdout(t, func() {
+		if err := CheckPorts(context.Background(), nil, CheckOptions{Verbose: true}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, line := range []string{
+		"<permission denied>",
+		"user:  <permission denied>",
+		"Note: 3 of 6 row(s) are incomplete due to missing privileges",
+	} {
+		if !strings.Contains(table, line) {
+			t.Errorf("table output lacks %q:\n%s", line, table)
+		}
+	}
+}
+
+func TestCheckPortsFilters(t *testing.T) {
+	tests := []struct {
+		opts CheckOptions
+		want int
+	}{
+		{CheckOptions{Processes: []string{"python3"}}, 1},
+		// 每个监听套接字一行：sshd 的 IPv4 和 IPv6 监听，nginx 的 master 和 worker
+		{CheckOptions{Processes: []string{"nginx", "sshd"}}, 4},
+		{CheckOptions{PIDs: []int{1201}}, 1},
+		{CheckOptions{Users: []string{"ALICE"}}, 1},
+		{CheckOptions{Processes: []string{"nginx"}, Users: []string{"alice"}}, 0},
+	}
+
+	for _, tt := range tests {
+		fakeHosts[0].install(t)
+
+		var ports []types.PortInfo
+		tt.opts.Output = OutputJSON
+		output := captureStdout(t, func() {
+			CheckPorts(context.Background(), nil, tt.opts)
+		})
+		if err := json.Unmarshal([]byte(output), &ports); err != nil {
+			t.Fatalf("invalid JSON output: %v\n%s", err, output)
+		}
+		if len(ports) != tt.want {
+			t.Errorf("processes %v users %v: got %d ports, want %d:\n%s", tt.opts.Processes, tt.opts.Users, len(ports), tt.want, output)
+		}
+	}
+}
+
+func TestCheckPortsTranslation(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Unprivileged = true
+	// 进程名和用户名恰好是目录中的消息时也不能翻译
+	fake.Processes = append([]types.ProcessInfo(nil), linuxProcesses...)
+	fake.Processes[len(fake.Processes)-1].Name = "host"
+	fake.Users = map[int]string{4242: "host"}
+
+	i18n.SetLanguage(i18n.Chinese)
+	t.Cleanup(func() { i18n.SetLanguage(i18n.English) })
+
+	for _, opts := range []CheckOptions{{Verbose: true}, {GroupBy: GroupByUser}} {
+		output := captureStdout(t, func() {
+			if err := CheckPorts(context.Background(), nil, opts); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if !strings.Contains(output, "host") || strings.Contains(output, "主机") {
+			t.Errorf("%+v: process or user name translated:\n%s", opts, output)
+		}
+		if !strings.Contains(output, "<权限不足>") {
+			t.Errorf("%+v: placeholder not translated:\n%s", opts, output)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+)
+
+// DoctorOptions configures the doctor command
+type DoctorOptions struct {
+	// Output is the output format, OutputTable or OutputJSON
+	Output string
+}
+
+// RunDoctor probes the discovery backends of the platform manager and reports which
+// ones work, which one check uses and the host properties that limit what they see
+func RunDoctor(ctx context.Context, opts DoctorOptions) error {
+	if err := validateOutput(opts.Output); err != nil {
+		return err
+	}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	report := platform.Doctor(ctx, manager)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if opts.Output == OutputJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if report.Collector == "" {
+		return errorf(ErrBackendUnavailable, "no socket collector works on this host")
+	}
+	return nil
+}
+
+// printDoctorReport prints the report as aligned sections
+func printDoctorReport(report *platform.DoctorReport) {
+	manager := report.Manager
+	if report.CacheTTL > 0 {
+		manager += i18n.Sprintf(" (scans cached for %s)", report.CacheTTL)
+	}
+	collector := report.Collector
+	if collector == "" {
+		collector = i18n.T("none")
+	}
+
+	i18n.Printf("Platform:   %s\n", report.Platform)
+	i18n.Printf("Manager:    %s\n", manager)
+	i18n.Printf("Collector:  %s\n", collector)
+
+	fmt.Printf("\n%-10s %-32s %-10s %-8s %10s %8s\n", "BACKEND", "ROLE", "VERSION", "STATUS", "TIME", "ENTRIES")
+	fmt.Println(strings.Repeat("-", 83))
+	for _, backend := range report.Backends {
+		version, status, entries := "-", "ok", "-"
+		if backend.Version != "" {
+			version = backend.Version
+		}
+		if !backend.Available {
+			status = "failed"
+		}
+		if backend.Entries > 0 {
+			entries = fmt.Sprint(backend.Entries)
+		}
+		fmt.Printf("%-10s %s %-10s %s %8.1fms %8s\n", backend.Name, padRight(i18n.T(backend.Role), 32), version,
+			padRight(i18n.T(status), 8), float64(backend.Duration)/float64(time.Millisecond), entries)
+		if backend.Error != "" {
+			i18n.Printf("    error: %s\n", backend.Error)
+		}
+		if backend.VersionError != "" {
+			i18n.Printf("    version check failed: %s\n", backend.VersionError)
+		}
+		if backend.Note != "" {
+			fmt.Printf("    %s\n", i18n.T(backend.Note))
+		}
+	}
+
+	if len(report.Environment) > 0 {
+		i18n.Println("\nEnvironment:")
+		for _, fact := range report.Environment {
+			fmt.Printf("  %-18s %s\n", fact.Name, i18n.T(fact.Value))
+			if fact.Note != "" {
+				fmt.Printf("  %-18s %s\n", "", i18n.T(fact.Note))
+			}
+		}
+	}
+
+	if report.Elevated {
+		i18n.Println("\nPrivileges (elevated):")
+	} else {
+		i18n.Println("\nPrivileges (unprivileged):")
+	}
+	for _, privilege := range report.Privileges {
+		held := i18n.T("missing")
+		if privilege.Held {
+			held = i18n.T("held")
+		}
+		i18n.Printf("  %-18s %s needed to %s\n", privilege.Name, padRight(held, 8), i18n.T(privilege.Purpose))
+	}
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+)
+
+// Kinds of failure the commands report, so callers can branch on the outcome with
+// errors.Is instead of parsing messages. ExitCode maps each kind to an exit status.
+var (
+	// ErrNoMatch reports that no port or process matched the request
+	ErrNoMatch = errors.New("no match")
+	// ErrPermissionDenied reports processes that could not be identified or killed for
+	// lack of privileges
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrPartialFailure reports a release in which processes could not be killed for
+	// other reasons than privileges, or ports were still in use afterwards
+	ErrPartialFailure = errors.New("partial failure")
+	// ErrBackendUnavailable reports that no platform tool could list the sockets
+	ErrBackendUnavailable = errors.New("backend unavailable")
+	// ErrInvalidPattern reports a port, range, service name, pattern or other argument that
+	// could not be parsed
+	ErrInvalidPattern = errors.New("invalid pattern")
+	// ErrTimeout reports a platform tool or request that did not finish in time
+	ErrTimeout = errors.New("timeout")
+	// ErrProtectedProcess reports that every matching process is protected
+	ErrProtectedProcess = errors.New("protected process")
+	// ErrDifferences reports port states that differ from a snapshot; the differences are
+	// already printed, so the CLI only sets the exit status
+	ErrDifferences = errors.New("port states differ")
+	// ErrPolicyViolations reports listeners that violate the guard policy; the violations
+	// are already printed, so the CLI only sets the exit status
+	ErrPolicyViolations = errors.New("listeners violate the policy")
+)
+
+// Exit codes of the CLI, documented in the README; any other failure exits with 1 and
+// a cancelled command with 130
+const (
+	ExitOK                 = 0
+	ExitFailure            = 1
+	ExitInvalidPattern     = 2
+	ExitNoMatch            = 3
+	ExitPermissionDenied   = 4
+	ExitPartialFailure     = 5
+	ExitProtectedProcess   = 6
+	ExitBackendUnavailable = 7
+	ExitTimeout            = 8
+	ExitDifferences        = 9
+	ExitPolicyViolations   = 10
+)
+
+// kindError is an error of one of the kinds above; its message is unchanged by the kind
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+// Localize returns the message in the selected language
+func (e *kindError) Localize() string {
+	return i18n.Localize(e.err)
+}
+
+// errorf formats an error of the given kind with i18n.Errorf; like fmt.Errorf, a %w
+// verb wraps its operand
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: i18n.Errorf(format, args...)}
+}
+
+// WithKind returns err as an error of the given kind, keeping its message, e.g. to
+// report invalid command-line arguments as ErrInvalidPattern
+func WithKind(kind error, err error) error {
+	return &kindError{kind: kind, err: err}
+}
+
+// collectError wraps a failure to list the sockets, as a timeout if a platform tool or
+// the context timed out and as an unavailable backend otherwise
+func collectError(err error) error {
+	return errorf(backendKind(err), "failed to get port connections: %w", err)
+}
+
+// backendKind returns the kind of a failure of the platform tools: ErrTimeout if a tool
+// or the context timed out, ErrBackendUnavailable otherwise
+func backendKind(err error) error {
+	var timeoutErr *platform.TimeoutError
+	if errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return ErrBackendUnavailable
+}
+
+// ExitCode returns the exit status for an error returned by the commands
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrInvalidPattern):
+		return ExitInvalidPattern
+	case errors.Is(err, ErrNoMatch):
+		return ExitNoMatch
+	case errors.Is(err, ErrPermissionDenied):
+		return ExitPermissionDenied
+	case errors.Is(err, ErrPartialFailure):
+		return ExitPartialFailure
+	case errors.Is(err, ErrProtectedProcess):
+		return ExitProtectedProcess
+	case errors.Is(err, ErrTimeout):
+		return ExitTimeout
+	case errors.Is(err, ErrBackendUnavailable):
+		return ExitBackendUnavailable
+	case errors.Is(err, ErrDifferences):
+		return ExitDifferences
+	case errors.Is(err, ErrPolicyViolations):
+		return ExitPolicyViolations
+	}
+	return ExitFailure
+}
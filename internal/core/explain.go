@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+	"portreleasor/internal/utils"
+)
+
+// ExplainPort diagnoses why a port cannot be bound and suggests a remediation for each finding
+func ExplainPort(ctx context.Context, portInput string) error {
+	ports, err := utils.ParsePorts([]string{portInput})
+	if err != nil {
+		return errorf(ErrInvalidPattern, "%w", err)
+	}
+	if len(ports) != 1 {
+		return errorf(ErrInvalidPattern, "explain takes a single port, got %q", portInput)
+	}
+	port := ports[0]
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	i18n.Printf("Diagnosing port %d...\n\n", port)
+
+	i18n.Println("Bind test:")
+	bindFailed := false
+	for _, network := range []string{"tcp", "udp"} {
+		result := i18n.T("available")
+		if err := bindTest(network, port); err != nil {
+			result = err.Error()
+			bindFailed = true
+		}
+		fmt.Printf("  %s :%d  %s\n", strings.ToUpper(network), port, result)
+	}
+
+	var findings []types.Finding
+
+	sockets, err := manager.GetAllSockets(ctx)
+	if err != nil {
+		i18n.Printf("\nCould not list sockets: %s\n", i18n.Localize(err))
+	} else {
+		findings = append(findings, ownerFindings(port, sockets)...)
+	}
+
+	findings = append(findings, platform.DiagnosePort(ctx, port)...)
+
+	if len(findings) == 0 {
+		if bindFailed {
+			i18n.Printf("\nNo cause found for port %d being unavailable.\n", port)
+		} else {
+			i18n.Printf("\nPort %d is available, nothing to explain.\n", port)
+		}
+	}
+
+	for i, finding := range findings {
+		fmt.Printf("\n[%d] %s\n", i+1, finding.Summary)
+		for _, detail := range finding.Details {
+			fmt.Printf("    - %s\n", detail)
+		}
+		i18n.Printf("    Remediation: %s\n", finding.Remediation)
+	}
+
+	if runtime.GOOS != "linux" {
+		i18n.Println("\nNote: namespace, TIME_WAIT, reserved-port, privileged-port and kernel-socket checks are only available on Linux")
+	}
+
+	return nil
+}
+
+// bindTest tries to bind the port on all addresses and returns the bind error, if any
+func bindTest(network string, port int) error {
+	addr := fmt.Sprintf(":%d", port)
+
+	var err error
+	if network == "udp" {
+		var conn net.PacketConn
+		if conn, err = net.ListenPacket(network, addr); err == nil {
+			conn.Close()
+		}
+	} else {
+		var listener net.Listener
+		if listener, err = net.Listen(network, addr); err == nil {
+			listener.Close()
+		}
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Err != nil {
+		// 只保留 "bind: address already in use" 这样的系统错误
+		return opErr.Err
+	}
+	return err
+}
+
+// ownerFindings reports the listeners on the port that the platform collector can see
+func ownerFindings(port int, sockets []types.PortInfo) []types.Finding {
+	var owned, hidden []string
+	for _, sock := range sockets {
+		if sock.Port != port || !platform.IsListening(sock) {
+			continue
+		}
+		if sock.PID > 0 {
+			owned = append(owned, i18n.Sprintf("%d/%s %s held by PID %d (%s)",
+				sock.Port, sock.Protocol, sock.LocalAddr, sock.PID, sock.ProcessName))
+		} else {
+			hidden = append(hidden, fmt.Sprintf("%d/%s %s", sock.Port, sock.Protocol, sock.LocalAddr))
+		}
+	}
+
+	var findings []types.Finding
+	if len(owned) > 0 {
+		findings = append(findings, types.Finding{
+			Check:       "owner",
+			Summary:     i18n.Sprintf("Port %d is held by a running process", port),
+			Details:     owned,
+			Remediation: i18n.Sprintf("Stop the service, or run `portreleasor release %d` to terminate it.", port),
+		})
+	}
+	if len(hidden) > 0 {
+		findings = append(findings, types.Finding{
+			Check:   "hidden-owner",
+			Summary: i18n.Sprintf("Port %d is bound but its owning process is not visible", port),
+			Details: hidden,
+			Remediation: i18n.T("Re-run as root/Administrator to resolve the owner. " +
+				"If it is still missing, the socket belongs to the kernel (see the other findings)."),
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"portreleasor/internal/platform/platformtest"
+	"portreleasor/internal/types"
+)
+
+// fakeHost is a captured host: the socket listing of one platform format and its process table
+type fakeHost struct {
+	name      string
+	format    string
+	fixture   string
+	processes []types.ProcessInfo
+}
+
+var linuxProcesses = []types.ProcessInfo{
+	{PID: 1, PPID: 0, Name: "systemd"},
+	{PID: 612, PPID: 1, Name: "systemd-resolve"},
+	{PID: 1001, PPID: 1, Name: "sshd"},
+	{PID: 1200, PPID: 1, Name: "nginx"},
+	{PID: 1201, PPID: 1200, Name: "nginx"},
+	{PID: 1999, PPID: 1001, Name: "sshd"},
+	{PID: 2002, PPID: 1999, Name: "sshd"},
+	{PID: 3000, PPID: 2002, Name: "bash"},
+	{PID: 4242, PPID: 3000, Name: "python3"},
+}
+
+var fakeHosts = []fakeHost{
+	{"linux-ss", platformtest.FormatSS, "ss_tunap.txt", linuxProcesses},
+	{"linux-netstat", platformtest.FormatNetstat, "netstat_tunap.txt", linuxProcesses},
+	{"darwin-lsof", platformtest.FormatLsof, "lsof_darwin.txt", []types.ProcessInfo{
+		{PID: 1, PPID: 0, Name: "launchd"},
+		{PID: 312, PPID: 1, Name: "mDNSResponder"},
+		{PID: 512, PPID: 1, Name: "ControlCenter"},
+		{PID: 3000, PPID: 2999, Name: "-zsh"},
+		{PID: 4242, PPID: 3000, Name: "Python"},
+		{PID: 6001, PPID: 1, Name: "Google Chrome"},
+	}},
+	{"windows-netstat-ano", platformtest.FormatNetstatANO, "netstat_ano.txt", []types.ProcessInfo{
+		{PID: 0, PPID: 0, Name: "System Idle Process"},
+		{PID: 4, PPID: 0, Name: "System"},
+		{PID: 1000, PPID: 700, Name: "svchost.exe"},
+		{PID: 2400, PPID: 700, Name: "mDNSResponder.exe"},
+		{PID: 3000, PPID: 2900, Name: "cmd.exe"},
+		{PID: 4242, PPID: 3000, Name: "python.exe"},
+		{PID: 6001, PPID: 2900, Name: "chrome.exe"},
+	}},
+}
+
+// install serves the host through a fake platform manager for the duration of the test
+func (h fakeHost) install(t *testing.T) *platformtest.FakeManager {
+	t.Helper()
+
+	output, err := os.ReadFile(filepath.Join("..", "platform", "testdata", h.fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &platformtest.FakeManager{
+		Replay:    platformtest.Replay{Format: h.format, Output: string(output)},
+		Processes: h.processes,
+		Users:     map[int]string{4242: "alice"},
+	}
+	t.Cleanup(fake.Install())
+	return fake
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	fn()
+	w.Close()
+	return string(<-done)
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/types"
+)
+
+// Supported --group-by values
+const (
+	GroupByProcess   = "process"
+	GroupByUser      = "user"
+	GroupByContainer = "container"
+	GroupByProtocol  = "protocol"
+)
+
+// portGroup is a set of ports sharing the same grouping key
+type portGroup struct {
+	Key   string           `json:"key"`
+	Label string           `json:"label"`
+	Count int              `json:"count"`
+	Ports []types.PortInfo `json:"ports"`
+}
+
+// groupedOutput is the JSON document produced by check --group-by
+type groupedOutput struct {
+	GroupBy string      `json:"group_by"`
+	Total   int         `json:"total"`
+	Groups  []portGroup `json:"groups"`
+}
+
+// validateGroupBy checks that the grouping is supported
+func validateGroupBy(groupBy string) error {
+	switch groupBy {
+	case "", GroupByProcess, GroupByUser, GroupByContainer, GroupByProtocol:
+		return nil
+	}
+	return errorf(ErrInvalidPattern, "unsupported group %q (supported: %s, %s, %s, %s)",
+		groupBy, GroupByProcess, GroupByUser, GroupByContainer, GroupByProtocol)
+}
+
+// groupPorts groups the port records by the given key, ordered by key;
+// records keep their relative order within each group
+func groupPorts(connections []types.PortInfo, groupBy string) []portGroup {
+	byKey := make(map[string]*portGroup)
+	var keys []string
+
+	for _, conn := range connections {
+		key, label := groupKey(conn, groupBy)
+		group, exists := byKey[key]
+		if !exists {
+			group = &portGroup{Key: key, Label: label}
+			byKey[key] = group
+			keys = append(keys, key)
+		}
+		group.Ports = append(group.Ports, conn)
+		group.Count++
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		// 按进程分组时按PID数值排序
+		if groupBy == GroupByProcess {
+			a, _ := strconv.Atoi(keys[i])
+			b, _ := strconv.Atoi(keys[j])
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+
+	groups := make([]portGroup, 0, len(keys))
+	for _, key := range keys {
+		groups = append(groups, *byKey[key])
+	}
+
+	return groups
+}
+
+// groupKey returns the grouping key and its display label for a port record
+func groupKey(conn types.PortInfo, groupBy string) (string, string) {
+	switch groupBy {
+	case GroupByProcess:
+		// 标签也用于JSON输出，不翻译
+		name := groupLabel(conn, types.FieldProcessName, conn.ProcessName)
+		return strconv.Itoa(conn.PID), fmt.Sprintf("%s (PID %d)", name, conn.PID)
+	case GroupByUser:
+		user := groupLabel(conn, types.FieldUser, conn.User)
+		return user, user
+	case GroupByContainer:
+		if conn.Container == "" {
+			return "host", "host"
+		}
+		return conn.Container, conn.Container
+	default:
+		return conn.Protocol, conn.Protocol
+	}
+}
+
+// groupLabel returns the value of a field, the untranslated reason it could not be
+// resolved, e.g. "<permission denied>", or "N/A"
+func groupLabel(conn types.PortInfo, field string, value string) string {
+	if value != "" {
+		return value
+	}
+	if status := conn.StatusOf(field); status != "" && status != types.StatusOK {
+		return "<" + strings.ReplaceAll(string(status), "-", " ") + ">"
+	}
+	return "N/A"
+}
+
+// printGroupTree prints the groups as a tree with a port count per group
+func printGroupTree(groups []portGroup, groupBy string) {
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		// 只翻译占位标签，用户名等原样输出
+		label := group.Label
+		switch first := group.Ports[0]; {
+		case groupBy == GroupByProcess:
+			label = ownerLabel(first)
+		case groupBy == GroupByUser:
+			label = valueOrNA(fieldText(first, types.FieldUser, first.User))
+		case groupBy == GroupByContainer && first.Container == "":
+			label = i18n.T("host")
+		}
+		i18n.Printf("%s  [%d port(s)]\n", label, group.Count)
+
+		for j, conn := range group.Ports {
+			branch := "├─"
+			if j == len(group.Ports)-1 {
+				branch = "└─"
+			}
+
+			line := fmt.Sprintf("%s %-12s %-12s %-22s", branch,
+				fmt.Sprintf("%d/%s", conn.Port, conn.Protocol), serviceLabel(conn), conn.LocalAddr)
+			if groupBy != GroupByProcess {
+				line += fmt.Sprintf(" %d %s", conn.PID, fieldText(conn, types.FieldProcessName, conn.ProcessName))
+			}
+			fmt.Println(strings.TrimRight(line, " "))
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+	"portreleasor/internal/utils"
+)
+
+// GuardOptions controls the policy guard
+type GuardOptions struct {
+	// PolicyPath is the YAML (or JSON) policy file
+	PolicyPath string
+	// Once checks the listeners a single time and returns ErrPolicyViolations if any violate the policy
+	Once bool
+}
+
+// webhookTimeout bounds each webhook request, which is sent synchronously during a scan
+const webhookTimeout = 5 * time.Second
+
+// Policy is the allowlist of listeners enforced by guard
+type Policy struct {
+	// Interval is how often the listeners are rescanned, default 5s
+	Interval string `yaml:"interval"`
+	// Webhook receives a JSON POST for each new, released and resolved violation
+	Webhook string `yaml:"webhook"`
+	// Release kills the owner of a violating listener once it has persisted for GracePeriod
+	Release bool `yaml:"release"`
+	// GracePeriod is how long a violation may persist before it is released, default 30s
+	GracePeriod string       `yaml:"grace_period"`
+	Allow       []PolicyRule `yaml:"allow"`
+
+	interval    time.Duration
+	gracePeriod time.Duration
+}
+
+// PolicyRule allows the listeners matching every field it sets
+type PolicyRule struct {
+	// Port is a port, range or service name, e.g. 22, 8000-8100 or https
+	Port string `yaml:"port"`
+	// Protocol is tcp or udp
+	Protocol string `yaml:"protocol"`
+	// Address is the local IP address the socket is bound to, e.g. 127.0.0.1 or ::
+	Address string `yaml:"address"`
+	// Process is a process name, wildcards such as python* are allowed
+	Process string `yaml:"process"`
+	// User is the owner of the process
+	User string `yaml:"user"`
+
+	ports map[int]bool
+}
+
+// guardEvent is the payload posted to the webhook
+type guardEvent struct {
+	Event  string         `json:"event"`
+	Host   string         `json:"host"`
+	Time   time.Time      `json:"time"`
+	Port   types.PortInfo `json:"port"`
+	Detail string         `json:"detail,omitempty"`
+}
+
+// violation is a listener that does not match the policy
+type violation struct {
+	info  types.PortInfo
+	since time.Time
+	// released is set once the owner was killed or found to be protected
+	released bool
+}
+
+// Guard watches the listening sockets and reports, and optionally releases, the ones
+// not allowed by the policy
+func Guard(ctx context.Context, opts GuardOptions) error {
+	policy, err := loadPolicy(opts.PolicyPath)
+	if err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	g := guard{
+		policy:     policy,
+		host:       host,
+		violations: make(map[string]*violation),
+		client:     &http.Client{Timeout: webhookTimeout},
+	}
+
+	if opts.Once {
+		count, err := g.scan(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPolicyViolations
+		}
+		i18n.Println("All listeners are allowed by the policy")
+		return nil
+	}
+
+	i18n.Printf("Guarding listeners with %d allow rule(s), rescanning every %s\n", len(policy.Allow), policy.interval)
+	if policy.Release {
+		i18n.Printf("Violations are released after %s\n", policy.gracePeriod)
+	}
+
+	ticker := time.NewTicker(policy.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.scan(ctx, time.Now()); err != nil {
+			g.log("scan failed: %s", i18n.Localize(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadPolicy reads and validates a policy file
+func loadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("failed to read policy: %v", err)
+	}
+
+	var policy Policy
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, i18n.Errorf("invalid policy %s: %v", path, err)
+	}
+
+	if policy.interval, err = parsePolicyDuration(policy.Interval, 5*time.Second); err != nil {
+		return nil, i18n.Errorf("invalid interval: %v", err)
+	}
+	if policy.gracePeriod, err = parsePolicyDuration(policy.GracePeriod, 30*time.Second); err != nil {
+		return nil, i18n.Errorf("invalid grace_period: %v", err)
+	}
+
+	for i := range policy.Allow {
+		rule := &policy.Allow[i]
+		if rule.Port != "" {
+			ports, err := utils.ParsePorts(strings.Split(rule.Port, ","))
+			if err != nil {
+				return nil, i18n.Errorf("allow rule %d: %v", i+1, err)
+			}
+			rule.ports = make(map[int]bool)
+			for _, port := range ports {
+				rule.ports[port] = true
+			}
+		}
+		if rule.Protocol != "" && !strings.EqualFold(rule.Protocol, "tcp") && !strings.EqualFold(rule.Protocol, "udp") {
+			return nil, i18n.Errorf("allow rule %d: protocol must be tcp or udp, got %q", i+1, rule.Protocol)
+		}
+		if rule.Address != "" && net.ParseIP(rule.Address) == nil && rule.Address != "*" {
+			return nil, i18n.Errorf("allow rule %d: invalid address %q", i+1, rule.Address)
+		}
+		if _, err := filepath.Match(rule.Process, ""); err != nil {
+			return nil, i18n.Errorf("allow rule %d: invalid process pattern %q", i+1, rule.Process)
+		}
+	}
+
+	return &policy, nil
+}
+
+// parsePolicyDuration parses a duration, returning the default when empty
+func parsePolicyDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, i18n.Errorf("must be positive, got %s", value)
+	}
+	return d, nil
+}
+
+// Match reports whether the rule allows the listener
+func (r PolicyRule) Match(info types.PortInfo) bool {
+	if r.ports != nil && !r.ports[info.Port] {
+		return false
+	}
+	if r.Protocol != "" && !strings.EqualFold(r.Protocol, info.Protocol) {
+		return false
+	}
+	if r.Address != "" && r.Address != "*" {
+		host, _, err := net.SplitHostPort(info.LocalAddr)
+		if err != nil {
+			return false
+		}
+		// 去掉 IPv6 区域标识，如 fe80::1%eth0
+		host, _, _ = strings.Cut(host, "%")
+		ruleIP, hostIP := net.ParseIP(r.Address), net.ParseIP(host)
+		if hostIP == nil || !ruleIP.Equal(hostIP) {
+			return false
+		}
+	}
+	if r.Process != "" && !matchProcessPattern(r.Process, info.ProcessName) {
+		return false
+	}
+	if r.User != "" && r.User != info.User {
+		return false
+	}
+	return true
+}
+
+// matchProcessPattern matches a process name against a wildcard pattern, ignoring case
+// and the .exe suffix on Windows
+func matchProcessPattern(pattern, name string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return matchProcessName(name, pattern)
+	}
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	matched, _ := filepath.Match(strings.ToLower(pattern), name)
+	return matched
+}
+
+// guard tracks the violations seen across scans
+type guard struct {
+	policy     *Policy
+	host       string
+	violations map[string]*violation
+	client     *http.Client
+}
+
+// scan checks the current listeners against the policy and returns the number of violations
+func (g *guard) scan(ctx context.Context, now time.Time) (int, error) {
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return 0, errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	// 同一端口可能有多个监听者（不同地址或共享套接字的进程），需要逐一检查
+	connections, err := listeningSockets(ctx, manager)
+	if err != nil {
+		return 0, err
+	}
+
+	users := make(map[int]string)
+	current := make(map[string]types.PortInfo)
+	for _, conn := range connections {
+		if conn.PID > 0 {
+			user, cached := users[conn.PID]
+			if !cached {
+				user, _ = manager.GetProcessUser(ctx, conn.PID)
+				users[conn.PID] = user
+			}
+			conn.User = user
+		}
+
+		if g.allowed(conn) {
+			continue
+		}
+		// 进程重启后 PID 变化视为新的违规，重新计算宽限期
+		current[fmt.Sprintf("%s/%d", snapshotKey(conn), conn.PID)] = conn
+	}
+
+	for key, info := range current {
+		if _, seen := g.violations[key]; !seen {
+			g.violations[key] = &violation{info: info, since: now}
+			g.log("violation: %s", describeListener(info))
+			g.notify(ctx, "violation", info, "")
+		}
+	}
+
+	for key, v := range g.violations {
+		if _, exists := current[key]; !exists {
+			delete(g.violations, key)
+			if !v.released {
+				g.log("resolved: %s", describeListener(v.info))
+				g.notify(ctx, "resolved", v.info, "")
+			}
+			continue
+		}
+
+		if g.policy.Release && !v.released && now.Sub(v.since) >= g.policy.gracePeriod {
+			v.released = true
+			g.release(ctx, manager, v.info)
+		}
+	}
+
+	return len(current), nil
+}
+
+// allowed reports whether any allow rule matches the listener
+func (g *guard) allowed(info types.PortInfo) bool {
+	for _, rule := range g.policy.Allow {
+		if rule.Match(info) {
+			return true
+		}
+	}
+	return false
+}
+
+// release kills the owner of a violating listener unless it is protected
+func (g *guard) release(ctx context.Context, manager platform.PlatformManager, info types.PortInfo) {
+	if reason := protectionReason(info.PID, info.ProcessName); reason != "" {
+		g.log("not releasing %s: %s", describeListener(info), i18n.T(reason))
+		g.notify(ctx, "release-skipped", info, reason)
+		return
+	}
+
+	if err := manager.KillProcessByPID(ctx, info.PID); err != nil {
+		g.log("failed to release %s: %s", describeListener(info), i18n.Localize(err))
+		g.notify(ctx, "release-failed", info, err.Error())
+		return
+	}
+
+	g.log("released: %s", describeListener(info))
+	g.notify(ctx, "released", info, "")
+}
+
+// notify posts an event to the webhook, if one is configured. The request is bounded by
+// the client timeout and aborted when ctx is cancelled
+func (g *guard) notify(ctx context.Context, event string, info types.PortInfo, detail string) {
+	if g.policy.Webhook == "" {
+		return
+	}
+
+	body, err := json.Marshal(guardEvent{Event: event, Host: g.host, Time: time.Now(), Port: info, Detail: detail})
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.policy.Webhook, bytes.NewReader(body))
+	if err != nil {
+		g.log("webhook failed: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		g.log("webhook failed: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		g.log("webhook failed: %s", resp.Status)
+	}
+}
+
+// log prints a timestamped guard message, translating format
+func (g *guard) log(format string, args ...interface{}) {
+	fmt.Printf("%s %s\n", time.Now().Format(time.RFC3339), i18n.Sprintf(format, args...))
+}
+
+// describeListener describes a listener and its owner on one line
+func describeListener(info types.PortInfo) string {
+	owner := ownerLabel(info)
+	if info.User != "" {
+		owner += i18n.Sprintf(", user %s", info.User)
+	}
+	return i18n.Sprintf("%s on %s by %s", portLabel(info), info.LocalAddr, owner)
+}
@@ -0,0 +1,326 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"portreleasor/internal/types"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	policy, err := loadPolicy(writePolicy(t, `
+allow:
+  - port: 22,https
+    protocol: TCP
+  - port: 8000-8100
+    address: 127.0.0.1
+    process: "python*"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.interval != 5*time.Second || policy.gracePeriod != 30*time.Second {
+		t.Errorf("interval %s and grace period %s, want the defaults 5s and 30s", policy.interval, policy.gracePeriod)
+	}
+	if ports := policy.Allow[0].ports; !ports[22] || !ports[443] || len(ports) != 2 {
+		t.Errorf("rule 1 ports = %v, want 22 and 443", ports)
+	}
+	if ports := policy.Allow[1].ports; len(ports) != 101 {
+		t.Errorf("rule 2 has %d ports, want 101", len(ports))
+	}
+
+	for name, content := range map[string]string{
+		"unknown field":     "allow:\n  - port: 22\n    pid: 1\n",
+		"interval":          "interval: soon\n",
+		"negative grace":    "grace_period: -1s\n",
+		"port":              "allow:\n  - port: 70000\n",
+		"protocol":          "allow:\n  - protocol: sctp\n",
+		"address":           "allow:\n  - address: localhost\n",
+		"process pattern":   "allow:\n  - process: \"python[\"\n",
+		"not a policy list": "allow: 22\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadPolicy(writePolicy(t, content)); err == nil {
+				t.Errorf("loadPolicy accepted %q", content)
+			}
+		})
+	}
+
+	if _, err := loadPolicy(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("loadPolicy accepted a missing file")
+	}
+}
+
+func TestPolicyRuleMatch(t *testing.T) {
+	python := types.PortInfo{Port: 8080, Protocol: "TCP", LocalAddr: "127.0.0.1:8080", ProcessName: "python3", User: "alice"}
+	zoned := types.PortInfo{Port: 546, Protocol: "UDP", LocalAddr: "[fe80::1%eth0]:546", ProcessName: "dhclient"}
+	chrome := types.PortInfo{Port: 5353, Protocol: "UDP", LocalAddr: "0.0.0.0:5353", ProcessName: "chrome.exe"}
+
+	tests := []struct {
+		name string
+		rule PolicyRule
+		info types.PortInfo
+		want bool
+	}{
+		{"empty rule", PolicyRule{}, python, true},
+		{"port", PolicyRule{Port: "8000-8100"}, python, true},
+		{"other port", PolicyRule{Port: "22"}, python, false},
+		{"protocol ignores case", PolicyRule{Protocol: "tcp"}, python, true},
+		{"other protocol", PolicyRule{Protocol: "udp"}, python, false},
+		{"address", PolicyRule{Address: "127.0.0.1"}, python, true},
+		{"other address", PolicyRule{Address: "0.0.0.0"}, python, false},
+		{"any address", PolicyRule{Address: "*"}, python, true},
+		{"zoned address", PolicyRule{Address: "fe80::1"}, zoned, true},
+		{"process", PolicyRule{Process: "python3"}, python, true},
+		{"process wildcard", PolicyRule{Process: "py*"}, python, true},
+		{"process without .exe", PolicyRule{Process: "Chrome"}, chrome, true},
+		{"wildcard without .exe", PolicyRule{Process: "chr*"}, chrome, true},
+		{"other process", PolicyRule{Process: "node"}, python, false},
+		{"user", PolicyRule{User: "alice"}, python, true},
+		{"other user", PolicyRule{User: "bob"}, python, false},
+		{"every field", PolicyRule{Port: "8080", Protocol: "tcp", Address: "127.0.0.1", Process: "python*", User: "alice"}, python, true},
+		{"one field differs", PolicyRule{Port: "8080", Protocol: "tcp", Address: "127.0.0.1", Process: "python*", User: "bob"}, python, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := loadPolicy(writePolicyRules(t, tt.rule))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policy.Allow[0].Match(tt.info); got != tt.want {
+				t.Errorf("%+v.Match(%s) = %v, want %v", tt.rule, tt.info.LocalAddr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardScanEveryListener(t *testing.T) {
+	fakeHosts[0].install(t)
+	// 只允许 IPv4 上的 sshd，[::]:22 即使与 0.0.0.0:22 端口相同也是违规
+	g := newTestGuard(t, `
+allow:
+  - port: 22
+    address: 0.0.0.0
+  - port: 53,80,2049
+  - port: 8080
+    user: alice
+`, "")
+
+	var count int
+	output := captureStdout(t, func() {
+		var err error
+		if count, err = g.scan(context.Background(), time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if count != 1 || !strings.Contains(output, "[::]:22") {
+		t.Errorf("scan found %d violation(s), want [::]:22:\n%s", count, output)
+	}
+}
+
+func TestGuardGracePeriod(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	webhook := newTestWebhook(t)
+	g := newTestGuard(t, `
+release: true
+grace_period: 30s
+allow:
+  - port: 22,53,80,2049
+`, webhook.server.URL)
+
+	start := time.Now()
+	scan := func(after time.Duration) int {
+		t.Helper()
+		var count int
+		captureStdout(t, func() {
+			var err error
+			if count, err = g.scan(context.Background(), start.Add(after)); err != nil {
+				t.Fatal(err)
+			}
+		})
+		return count
+	}
+
+	if count := scan(0); count != 1 {
+		t.Fatalf("scan found %d violation(s), want 8080", count)
+	}
+	scan(29 * time.Second)
+	if got := fake.Killed(); len(got) != 0 {
+		t.Fatalf("killed %v within the grace period", got)
+	}
+
+	scan(30 * time.Second)
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+		t.Fatalf("killed %v after the grace period, want [4242]", got)
+	}
+
+	// 释放后的监听者消失，不再计为违规，也不会重复通知
+	if count := scan(31 * time.Second); count != 0 {
+		t.Errorf("scan found %d violation(s) after the release, want none", count)
+	}
+
+	var events []string
+	for _, event := range webhook.received() {
+		events = append(events, event.Event)
+		if event.Port.Port != 8080 || event.Port.PID != 4242 || event.Port.User != "alice" {
+			t.Errorf("%s event for %+v, want python3 on 8080", event.Event, event.Port)
+		}
+	}
+	if want := []string{"violation", "released"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("webhook received %v, want %v", events, want)
+	}
+}
+
+func TestGuardOnce(t *testing.T) {
+	fakeHosts[0].install(t)
+	path := writePolicy(t, "allow:\n  - port: 22,53,80,2049\n")
+
+	captureStdout(t, func() {
+		err := Guard(context.Background(), GuardOptions{PolicyPath: path, Once: true})
+		if !errors.Is(err, ErrPolicyViolations) || ExitCode(err) != ExitPolicyViolations {
+			t.Errorf("Guard returned %v (exit %d), want ErrPolicyViolations (exit %d)", err, ExitCode(err), ExitPolicyViolations)
+		}
+	})
+}
+
+func TestGuardNotify(t *testing.T) {
+	webhook := newTestWebhook(t)
+	g := newTestGuard(t, "allow: []\n", webhook.server.URL)
+	info := types.PortInfo{Port: 8080, Protocol: "TCP", LocalAddr: "0.0.0.0:8080", PID: 4242, ProcessName: "python3"}
+
+	captureStdout(t, func() {
+		g.notify(context.Background(), "release-skipped", info, "protected")
+	})
+
+	events := webhook.received()
+	if len(events) != 1 {
+		t.Fatalf("webhook received %d event(s), want 1", len(events))
+	}
+	if got := events[0]; got.Event != "release-skipped" || got.Host != "test-host" || got.Detail != "protected" || got.Port.Port != info.Port || got.Port.PID != info.PID {
+		t.Errorf("webhook received %+v", got)
+	}
+	webhook.mu.Lock()
+	defer webhook.mu.Unlock()
+	if webhook.contentType != "application/json" {
+		t.Errorf("webhook Content-Type = %q, want application/json", webhook.contentType)
+	}
+}
+
+func TestGuardNotifyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	g := newTestGuard(t, "allow: []\n", server.URL)
+	g.client.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	output := captureStdout(t, func() {
+		g.notify(context.Background(), "violation", types.PortInfo{Port: 8080}, "")
+	})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("notify took %s with a hung webhook", elapsed)
+	}
+	if !strings.Contains(output, "webhook failed") {
+		t.Errorf("notify did not log the timeout:\n%s", output)
+	}
+}
+
+// testWebhook records the events posted to it
+type testWebhook struct {
+	server      *httptest.Server
+	mu          sync.Mutex
+	events      []guardEvent
+	contentType string
+}
+
+func newTestWebhook(t *testing.T) *testWebhook {
+	t.Helper()
+
+	webhook := &testWebhook{}
+	webhook.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event guardEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		webhook.mu.Lock()
+		defer webhook.mu.Unlock()
+		webhook.events = append(webhook.events, event)
+		webhook.contentType = r.Header.Get("Content-Type")
+	}))
+	t.Cleanup(webhook.server.Close)
+	return webhook
+}
+
+func (w *testWebhook) received() []guardEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]guardEvent(nil), w.events...)
+}
+
+// newTestGuard loads the policy and returns a guard posting to webhook, if not empty
+func newTestGuard(t *testing.T, policy, webhook string) *guard {
+	t.Helper()
+
+	if webhook != "" {
+		policy = "webhook: " + webhook + "\n" + policy
+	}
+	loaded, err := loadPolicy(writePolicy(t, policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &guard{
+		policy:     loaded,
+		host:       "test-host",
+		violations: make(map[string]*violation),
+		client:     &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// writePolicyRules writes a policy with a single allow rule, so it is validated and
+// its ports are parsed like a policy file
+func writePolicyRules(t *testing.T, rule PolicyRule) string {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("allow:\n  - {")
+	for _, field := range []struct{ key, value string }{
+		{"port", rule.Port}, {"protocol", rule.Protocol}, {"address", rule.Address},
+		{"process", rule.Process}, {"user", rule.User},
+	} {
+		if field.value != "" {
+			b.WriteString(field.key + ": \"" + field.value + "\", ")
+		}
+	}
+	b.WriteString("}\n")
+	return writePolicy(t, b.String())
+}
+
+// writePolicy writes a policy file and returns its path
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
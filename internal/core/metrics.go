@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"portreleasor/internal/platform"
+)
+
+// ExporterOptions controls the standalone Prometheus exporter
+type ExporterOptions struct {
+	// Listen is the address to listen on, e.g. 127.0.0.1:9877
+	Listen string
+}
+
+// metricsHandler serves the Prometheus text exposition format, scanning the sockets on
+// every scrape
+type metricsHandler struct {
+	scrapeErrors atomic.Int64
+}
+
+// ExportMetrics serves /metrics until interrupted
+func ExportMetrics(ctx context.Context, opts ExporterOptions) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", newMetricsHandler())
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, `portreleasor exporter, metrics at /metrics`)
+	})
+
+	return listenAndServe(ctx, opts.Listen, mux, "metrics", "")
+}
+
+// newMetricsHandler returns a handler for the /metrics endpoint
+func newMetricsHandler() http.Handler {
+	return &metricsHandler{}
+}
+
+// ServeHTTP scans the sockets and writes the metrics
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	h.writeMetrics(r.Context(), w)
+}
+
+// writeMetrics writes the socket gauges followed by the collector's own health metrics
+func (h *metricsHandler) writeMetrics(ctx context.Context, w io.Writer) {
+	start := time.Now()
+	success := 1
+
+	listening := make(map[string]int)
+	connections := make(map[string]int)
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		success = 0
+	} else if sockets, err := manager.GetAllSockets(ctx); err != nil {
+		success = 0
+	} else {
+		for _, sock := range sockets {
+			protocol := strings.ToLower(sock.Protocol)
+			if platform.IsListening(sock) {
+				labels := fmt.Sprintf(`port="%d",protocol=%s,process=%s`,
+					sock.Port, quoteLabel(protocol), quoteLabel(sock.ProcessName))
+				listening[labels]++
+			} else {
+				labels := fmt.Sprintf(`protocol=%s,state=%s`, quoteLabel(protocol), quoteLabel(sock.State))
+				connections[labels]++
+			}
+		}
+	}
+
+	if success == 0 {
+		h.scrapeErrors.Add(1)
+	}
+
+	writeGauges(w, "portreleasor_listening_sockets",
+		"Listening sockets per port, protocol and owning process.", listening)
+	writeGauges(w, "portreleasor_connections",
+		"Non-listening sockets per protocol and state.", connections)
+
+	fmt.Fprintln(w, "# HELP portreleasor_scrape_duration_seconds Time taken to collect the sockets from the platform.")
+	fmt.Fprintln(w, "# TYPE portreleasor_scrape_duration_seconds gauge")
+	fmt.Fprintf(w, "portreleasor_scrape_duration_seconds %g\n", time.Since(start).Seconds())
+	fmt.Fprintln(w, "# HELP portreleasor_scrape_success Whether the last collection of the sockets succeeded.")
+	fmt.Fprintln(w, "# TYPE portreleasor_scrape_success gauge")
+	fmt.Fprintf(w, "portreleasor_scrape_success %d\n", success)
+	fmt.Fprintln(w, "# HELP portreleasor_scrape_errors_total Failed collections of the sockets since start.")
+	fmt.Fprintln(w, "# TYPE portreleasor_scrape_errors_total counter")
+	fmt.Fprintf(w, "portreleasor_scrape_errors_total %d\n", h.scrapeErrors.Load())
+}
+
+// writeGauges writes one gauge family with a sample per label set, in a stable order
+func writeGauges(w io.Writer, name string, help string, samples map[string]int) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+
+	keys := make([]string, 0, len(samples))
+	for labels := range samples {
+		keys = append(keys, labels)
+	}
+	sort.Strings(keys)
+
+	for _, labels := range keys {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, labels, samples[labels])
+	}
+}
+
+// quoteLabel quotes a label value, escaping backslashes, quotes and newlines
+func quoteLabel(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	fakeHosts[0].install(t)
+	handler := newMetricsHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /metrics = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`portreleasor_listening_sockets{port="8080",protocol="tcp",process="python3"} 1`,
+		`portreleasor_listening_sockets{port="22",protocol="tcp",process="sshd"} 2`,
+		`portreleasor_listening_sockets{port="80",protocol="tcp",process="nginx"} 2`,
+		`portreleasor_connections{protocol="tcp",state="ESTAB"} 3`,
+		"portreleasor_scrape_success 1\n",
+		"portreleasor_scrape_errors_total 0\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsHandlerScrapeError(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Format = "unknown"
+	handler := newMetricsHandler()
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{"portreleasor_scrape_success 0\n", "portreleasor_scrape_errors_total 3\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "portreleasor_listening_sockets{") {
+		t.Errorf("failed scrape reports sockets:\n%s", body)
+	}
+}
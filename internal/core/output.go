@@ -0,0 +1,211 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+)
+
+// Supported output formats
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// validateOutput checks that the output format is supported
+func validateOutput(output string) error {
+	switch output {
+	case "", OutputTable, OutputJSON:
+		return nil
+	}
+	return errorf(ErrInvalidPattern, "unsupported output format %q (supported: %s, %s)", output, OutputTable, OutputJSON)
+}
+
+// printJSON writes the value to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return i18n.Errorf("failed to encode JSON: %v", err)
+	}
+	return nil
+}
+
+// printPortTable prints the port records as an aligned table
+func printPortTable(filtered []types.PortInfo, verbose bool) {
+	// 使用固定宽度格式化输出
+	portProtocolWidth := 15
+	serviceWidth := 10
+	pidWidth := 8
+	processWidth := 20
+	pathWidth := 40
+
+	// 动态调整列宽以适应实际数据
+	for _, conn := range filtered {
+		portProtocol := fmt.Sprintf("%d/%s", conn.Port, conn.Protocol)
+		if len(portProtocol) > portProtocolWidth {
+			portProtocolWidth = len(portProtocol)
+		}
+		if len(conn.Service) > serviceWidth {
+			serviceWidth = len(conn.Service)
+		}
+		pidStr := fmt.Sprintf("%d", conn.PID)
+		if len(pidStr) > pidWidth {
+			pidWidth = len(pidStr)
+		}
+		if name := fieldText(conn, types.FieldProcessName, conn.ProcessName); displayWidth(name) > processWidth {
+			processWidth = displayWidth(name)
+		}
+		if path := fieldText(conn, types.FieldProcessPath, conn.ProcessPath); verbose && displayWidth(path) > pathWidth {
+			pathWidth = displayWidth(path)
+		}
+	}
+
+	// 添加适当的间距
+	portProtocolWidth += 2
+	serviceWidth += 2
+	pidWidth += 2
+	processWidth += 2
+	pathWidth += 2
+
+	// 打印表头
+	if verbose {
+		header := fmt.Sprintf("%-*s %-*s %-*s %-*s %s",
+			portProtocolWidth, "PORT/PROTOCOL",
+			serviceWidth, "SERVICE",
+			pidWidth, "PID",
+			processWidth, "PROCESS",
+			"PATH")
+		fmt.Println(header)
+		fmt.Println(strings.Repeat("-", portProtocolWidth+serviceWidth+pidWidth+processWidth+pathWidth+4))
+	} else {
+		header := fmt.Sprintf("%-*s %-*s %-*s %s",
+			portProtocolWidth, "PORT/PROTOCOL",
+			serviceWidth, "SERVICE",
+			pidWidth, "PID",
+			"PROCESS")
+		fmt.Println(header)
+		fmt.Println(strings.Repeat("-", portProtocolWidth+serviceWidth+pidWidth+processWidth+3))
+	}
+
+	// 打印数据行
+	for _, conn := range filtered {
+		if verbose {
+			line := fmt.Sprintf("%-*s %-*s %-*d %s %s",
+				portProtocolWidth, fmt.Sprintf("%d/%s", conn.Port, conn.Protocol),
+				serviceWidth, serviceLabel(conn),
+				pidWidth, conn.PID,
+				padRight(fieldText(conn, types.FieldProcessName, conn.ProcessName), processWidth),
+				fieldText(conn, types.FieldProcessPath, conn.ProcessPath))
+			fmt.Println(line)
+			printProcessDetails(conn, portProtocolWidth)
+		} else {
+			line := fmt.Sprintf("%-*s %-*s %-*d %s",
+				portProtocolWidth, fmt.Sprintf("%d/%s", conn.Port, conn.Protocol),
+				serviceWidth, serviceLabel(conn),
+				pidWidth, conn.PID,
+				fieldText(conn, types.FieldProcessName, conn.ProcessName))
+			fmt.Println(line)
+		}
+	}
+}
+
+// printProcessDetails prints the command line, working directory, start time, user and container
+// below a verbose row, or why they could not be resolved
+func printProcessDetails(conn types.PortInfo, indent int) {
+	pad := strings.Repeat(" ", indent+1)
+
+	startTime := ""
+	if conn.StartTime != nil {
+		startTime = conn.StartTime.Format(time.DateTime)
+	}
+
+	lines := []struct{ label, field, value string }{
+		{"cmd:  ", types.FieldCommandLine, conn.CommandLine},
+		{"cwd:  ", types.FieldCwd, conn.Cwd},
+		{"start:", types.FieldStartTime, startTime},
+		{"user: ", types.FieldUser, conn.User},
+		{"cont: ", types.FieldContainer, conn.Container},
+	}
+	for _, line := range lines {
+		if text := fieldText(conn, line.field, line.value); text != "" {
+			fmt.Printf("%s%s %s\n", pad, line.label, text)
+		}
+	}
+}
+
+// fieldText returns the value of a process field or, if it is empty because it could
+// not be resolved, the translated reason, e.g. "<permission denied>"
+func fieldText(conn types.PortInfo, field string, value string) string {
+	if value != "" {
+		return value
+	}
+	switch conn.StatusOf(field) {
+	case types.StatusPermissionDenied:
+		return i18n.T("<permission denied>")
+	case types.StatusProcessGone:
+		return i18n.T("<process gone>")
+	case types.StatusUnsupported:
+		return i18n.T("<unsupported>")
+	}
+	return ""
+}
+
+// printIncompleteSummary notes how many rows miss details for lack of privileges and
+// which privileges are missing, returning the number of such rows
+func printIncompleteSummary(ports []types.PortInfo) int {
+	denied := 0
+	for _, conn := range ports {
+		if conn.Denied() {
+			denied++
+		}
+	}
+	if denied == 0 {
+		return 0
+	}
+
+	i18n.Printf("Note: %d of %d row(s) are incomplete due to missing privileges", denied, len(ports))
+	if missing := describeMissing(platform.OpInspect); missing != "" {
+		i18n.Printf(" (missing %s)", missing)
+	}
+	if hint := elevationHint(); hint != "" {
+		i18n.Printf("; %s to see them", hint)
+	}
+	fmt.Println()
+	return denied
+}
+
+// displayWidth returns the number of terminal columns s occupies, counting East Asian
+// wide characters such as Chinese as two
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width++
+		if isWide(r) {
+			width++
+		}
+	}
+	return width
+}
+
+// isWide reports whether a rune is an East Asian wide or fullwidth character
+func isWide(r rune) bool {
+	return r >= 0x1100 && r <= 0x115F || r >= 0x2E80 && r <= 0xA4CF || r >= 0xAC00 && r <= 0xD7A3 ||
+		r >= 0xF900 && r <= 0xFAFF || r >= 0xFE30 && r <= 0xFE4F || r >= 0xFF00 && r <= 0xFF60 ||
+		r >= 0xFFE0 && r <= 0xFFE6
+}
+
+// padRight pads s with spaces to width terminal columns; fmt's %-*s counts runes, which
+// misaligns translated text
+func padRight(s string, width int) string {
+	if pad := width - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+)
+
+// ElevateFunc re-runs the tool with elevated privileges, e.g. through sudo, with the
+// given command line arguments or, if nil, the current ones. On success the current
+// process exits with the status of the elevated one, so it only returns on failure.
+type ElevateFunc func(args []string) error
+
+// describeMissing names the privileges op lacks and what they are needed for, empty
+// if none are known to be missing
+func describeMissing(op platform.Operation) string {
+	var missing []string
+	for _, privilege := range platform.MissingPrivileges(op) {
+		missing = append(missing, i18n.Sprintf("%s to %s", privilege.Name, i18n.T(privilege.Purpose)))
+	}
+	return strings.Join(missing, "; ")
+}
+
+// elevationHint tells the user how to run with the missing privileges, empty when the
+// process already runs as root or Administrator and only lacks capabilities
+func elevationHint() string {
+	if platform.Elevated() {
+		return ""
+	}
+	if _, err := platform.FindEscalator(); err == nil {
+		return i18n.T("re-run with --sudo")
+	}
+	if runtime.GOOS == "windows" {
+		return i18n.T("run from an Administrator prompt")
+	}
+	return i18n.T("run as root")
+}
+
+// offerElevation asks the translated question whether to re-run args with elevated privileges and does so if
+// confirmed. Nothing is asked unless elevate is set, the process is not elevated yet
+// and stdin is a terminal.
+func offerElevation(ctx context.Context, elevate ElevateFunc, question string, args []string) {
+	if elevate == nil || platform.Elevated() || !isTerminal(os.Stdin) {
+		return
+	}
+	if _, err := platform.FindEscalator(); err != nil {
+		return
+	}
+
+	fmt.Printf("\n%s (y/N): ", question)
+	response, _ := readLine(ctx, bufio.NewReader(os.Stdin))
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		return
+	}
+
+	if err := elevate(args); err != nil {
+		i18n.Printf("Could not elevate privileges: %s\n", i18n.Localize(err))
+	}
+}
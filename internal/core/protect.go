@@ -0,0 +1,42 @@
+package core
+
+import (
+	"os"
+	"strings"
+)
+
+// protectedProcessNames lists system processes whose termination would take down the host
+var protectedProcessNames = map[string]bool{
+	// Linux
+	"systemd":  true,
+	"init":     true,
+	"kthreadd": true,
+	// macOS
+	"launchd":     true,
+	"kernel_task": true,
+	// Windows
+	"system":              true,
+	"system idle process": true,
+	"smss.exe":            true,
+	"csrss.exe":           true,
+	"wininit.exe":         true,
+	"winlogon.exe":        true,
+	"services.exe":        true,
+	"lsass.exe":           true,
+}
+
+// protectionReason returns why a process must not be killed, or "" if it may be killed
+func protectionReason(pid int, processName string) string {
+	switch {
+	case pid <= 0:
+		// 无法确定进程，信号会发送给整个进程组
+		return "owner process unknown (insufficient privileges?)"
+	case pid == 1:
+		return "init process"
+	case pid == os.Getpid():
+		return "portreleasor itself"
+	case protectedProcessNames[strings.ToLower(processName)]:
+		return "critical system process"
+	}
+	return ""
+}
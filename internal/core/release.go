@@ -2,94 +2,689 @@ package core
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"portreleasor/internal/i18n"
 	"portreleasor/internal/platform"
 	"portreleasor/internal/types"
 	"portreleasor/internal/utils"
 )
 
-// ReleasePorts releases the specified ports by killing the processes using them
-func ReleasePorts(portInputs []string, force bool) error {
-	ports, err := utils.ParsePorts(portInputs)
+// ReleaseOptions controls how the processes holding the ports are confirmed and killed
+type ReleaseOptions struct {
+	// Force kills every matching process without asking
+	Force bool
+	// NonInteractive replaces the per-process selection with a single y/N
+	// confirmation, so the answer can be piped in from scripts
+	NonInteractive bool
+	// Elevate, if set, is offered to retry the processes that could not be killed for
+	// lack of privileges
+	Elevate ElevateFunc
+	// Prompt is where the confirmation is read from, os.Stdin if nil, e.g. the terminal
+	// when stdin carries the ports to release
+	Prompt *os.File
+}
+
+// ReleaseTarget groups all ports held by one process that a release would terminate
+type ReleaseTarget struct {
+	PID         int    `json:"pid"`
+	ProcessName string `json:"process_name"`
+	ProcessPath string `json:"process_path,omitempty"`
+	CommandLine string `json:"command_line,omitempty"`
+	// Protected holds the reason the process must not be killed, empty if it may be
+	Protected string           `json:"protected,omitempty"`
+	Ports     []types.PortInfo `json:"ports"`
+}
+
+// ReleaseResult is the outcome of terminating one target
+type ReleaseResult struct {
+	PID     int    `json:"pid"`
+	Killed  bool   `json:"killed"`
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// PermissionDenied is set when the process could not be killed for lack of privileges
+	PermissionDenied bool `json:"permission_denied,omitempty"`
+
+	// err is the kill error, whose message is translated when printed
+	err error
+}
+
+// ReleaseReport is the outcome of a non-interactive release
+type ReleaseReport struct {
+	DryRun  bool            `json:"dry_run"`
+	Targets []ReleaseTarget `json:"targets"`
+	Results []ReleaseResult `json:"results,omitempty"`
+	// StillInUse lists ports that were still held after the verification timeout
+	StillInUse []string `json:"still_in_use,omitempty"`
+}
+
+// 释放后等待端口空闲的时间
+const (
+	verifyTimeout  = 3 * time.Second
+	verifyInterval = 300 * time.Millisecond
+)
+
+// hiddenOwnerReason protects sockets whose owner the current user may not see
+const hiddenOwnerReason = "owner hidden, insufficient privileges to identify it"
+
+// ProcessFilter selects processes by name, PID or executable path
+type ProcessFilter struct {
+	Names []string `json:"processes,omitempty"`
+	PIDs  []int    `json:"pids,omitempty"`
+	Exes  []string `json:"exes,omitempty"`
+}
+
+// IsEmpty reports whether no selector was given
+func (f ProcessFilter) IsEmpty() bool {
+	return len(f.Names) == 0 && len(f.PIDs) == 0 && len(f.Exes) == 0
+}
+
+// Match reports whether the process owning the port record matches any selector
+func (f ProcessFilter) Match(conn types.PortInfo) bool {
+	for _, pid := range f.PIDs {
+		if conn.PID == pid {
+			return true
+		}
+	}
+	for _, name := range f.Names {
+		if matchProcessName(conn.ProcessName, name) {
+			return true
+		}
+	}
+	for _, exe := range f.Exes {
+		if conn.ProcessPath != "" && filepath.Clean(conn.ProcessPath) == filepath.Clean(exe) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchProcessName reports whether a process name matches the given name,
+// ignoring case and the Windows ".exe" suffix
+func matchProcessName(processName, name string) bool {
+	return strings.EqualFold(processName, name) ||
+		strings.EqualFold(strings.TrimSuffix(strings.ToLower(processName), ".exe"), name)
+}
+
+// ReleasePorts releases the specified ports by killing the processes using them. Ports
+// may be qualified by protocol and local address or interface, e.g. 53/udp,
+// 127.0.0.1:8080 or docker0:5432, to spare the other sockets on the same port.
+func ReleasePorts(ctx context.Context, portInputs []string, opts ReleaseOptions) error {
+	targets, err := utils.ParseTargets(portInputs)
+	if err != nil {
+		return errorf(ErrInvalidPattern, "%w", err)
+	}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	matched, err := targetSockets(ctx, manager, targets)
+	if err != nil {
+		return err
+	}
+	if len(matched) == 0 {
+		i18n.Println("No processes found using the specified ports")
+		return errorf(ErrNoMatch, "no processes found using the specified ports")
+	}
+
+	i18n.Println("Processes using the specified ports:")
+	return releaseConnections(ctx, manager, matched, opts)
+}
+
+// ReleaseProcesses releases every port held by the processes matching the filter
+func ReleaseProcesses(ctx context.Context, filter ProcessFilter, opts ReleaseOptions) error {
+	if filter.IsEmpty() {
+		return errorf(ErrInvalidPattern, "no process name, PID or executable specified")
+	}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	connections, err := listeningSockets(ctx, manager)
 	if err != nil {
 		return err
 	}
 
+	matched := matchProcesses(connections, filter)
+	if len(matched) == 0 {
+		i18n.Println("No ports held by the specified processes")
+		return errorf(ErrNoMatch, "no ports held by the specified processes")
+	}
+
+	i18n.Println("Ports held by the specified processes:")
+	return releaseConnections(ctx, manager, matched, opts)
+}
+
+// PlanRelease returns the processes that releasing the given ports and processes would
+// terminate, without killing anything
+func PlanRelease(ctx context.Context, portInputs []string, filter ProcessFilter) ([]ReleaseTarget, error) {
+	if len(portInputs) == 0 && filter.IsEmpty() {
+		return nil, errorf(ErrInvalidPattern, "no ports or processes specified")
+	}
+
+	targets, err := utils.ParseTargets(portInputs)
+	if err != nil {
+		return nil, errorf(ErrInvalidPattern, "%w", err)
+	}
+
 	manager := platform.GetPlatformManager()
 	if manager == nil {
-		return fmt.Errorf("unsupported platform")
+		return nil, errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	var matched []types.PortInfo
+	if len(targets) > 0 {
+		if matched, err = targetSockets(ctx, manager, targets); err != nil {
+			return nil, err
+		}
+	}
+	if !filter.IsEmpty() {
+		connections, err := listeningSockets(ctx, manager)
+		if err != nil {
+			return nil, err
+		}
+		matched = uniqueSockets(append(matched, matchProcesses(connections, filter)...))
 	}
 
-	connections, err := manager.GetPortConnections()
+	annotateServices(matched)
+	return groupByProcess(ctx, manager, matched), nil
+}
+
+// ExecuteRelease terminates the planned targets without prompting, skipping protected
+// processes, and verifies that their ports were freed
+func ExecuteRelease(ctx context.Context, targets []ReleaseTarget) ReleaseReport {
+	report := ReleaseReport{Targets: targets}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		for _, target := range targets {
+			report.Results = append(report.Results, ReleaseResult{PID: target.PID, Error: "unsupported platform"})
+		}
+		return report
+	}
+
+	var killed []ReleaseTarget
+	report.Results, killed = killTargets(ctx, manager, targets)
+	if len(killed) > 0 {
+		for _, held := range verifyReleased(ctx, killed) {
+			report.StillInUse = append(report.StillInUse, held.String())
+		}
+	}
+
+	return report
+}
+
+// targetSockets returns the listening sockets matching the targets, with their owners
+// resolved. The listening query keeps a single socket per port and protocol, so the
+// targets are matched against every listening socket instead, e.g. both the IPv4 and
+// IPv6 listener of a port and every nginx worker sharing it.
+func targetSockets(ctx context.Context, manager platform.PlatformManager, targets []utils.PortTarget) ([]types.PortInfo, error) {
+	listening, err := listeningSockets(ctx, manager)
 	if err != nil {
-		return fmt.Errorf("failed to get port connections: %v", err)
+		return nil, err
+	}
+	return matchTargets(listening, targets), nil
+}
+
+// listeningSockets returns every listening socket with its owner resolved, one entry
+// per owning process and bind address. Unlike GetListeningSockets, sockets sharing a
+// port and protocol are all kept, e.g. IPv4 and IPv6 listeners or nginx workers.
+func listeningSockets(ctx context.Context, manager platform.PlatformManager) ([]types.PortInfo, error) {
+	sockets, err := manager.GetAllSockets(ctx)
+	if err != nil {
+		return nil, collectError(err)
 	}
 
-	portMap := make(map[int][]types.PortInfo)
+	var listening []types.PortInfo
+	for _, socket := range sockets {
+		if platform.IsListening(socket) {
+			socket.RemoteAddr = ""
+			socket.State = "LISTENING"
+			listening = append(listening, socket)
+		}
+	}
+	listening = uniqueSockets(listening)
+	types.SortPortInfos(listening)
+	return listening, nil
+}
+
+// matchTargets returns the port records matching any of the targets
+func matchTargets(connections []types.PortInfo, targets []utils.PortTarget) []types.PortInfo {
+	var matched []types.PortInfo
 	for _, conn := range connections {
-		for _, port := range ports {
-			if conn.Port == port {
-				portMap[port] = append(portMap[port], conn)
+		for _, target := range targets {
+			if target.Match(conn.Port, conn.Protocol, conn.LocalAddr) {
+				matched = append(matched, conn)
+				break
 			}
 		}
 	}
+	return matched
+}
+
+// matchProcesses returns the port records owned by processes matching the filter
+func matchProcesses(connections []types.PortInfo, filter ProcessFilter) []types.PortInfo {
+	var matched []types.PortInfo
+	for _, conn := range connections {
+		if filter.Match(conn) {
+			matched = append(matched, conn)
+		}
+	}
+	return matched
+}
+
+// releaseConnections confirms, kills and verifies the processes owning the given port records
+func releaseConnections(ctx context.Context, manager platform.PlatformManager, matched []types.PortInfo, opts ReleaseOptions) error {
+	annotateServices(matched)
+	targets := groupByProcess(ctx, manager, matched)
+	printTargets(targets)
+
+	releasable, hidden := 0, 0
+	for _, target := range targets {
+		switch target.Protected {
+		case "":
+			releasable++
+		case hiddenOwnerReason:
+			hidden += len(target.Ports)
+		}
+	}
+	if hidden > 0 {
+		i18n.Printf("\nThe owner of %d port(s) is hidden for lack of privileges", hidden)
+		if missing := describeMissing(platform.OpInspect); missing != "" {
+			i18n.Printf(" (missing %s)", missing)
+		}
+		if hint := elevationHint(); hint != "" {
+			fmt.Printf("; %s", hint)
+		}
+		fmt.Println()
+	}
+	if releasable == 0 {
+		if hidden > 0 && !opts.Force && !opts.NonInteractive {
+			offerElevation(ctx, opts.Elevate, i18n.T("Re-run with elevated privileges to release them?"), nil)
+		}
+		i18n.Println("\nAll matching processes are protected, nothing to release")
+		if hidden > 0 {
+			return errorf(ErrPermissionDenied, "refusing to kill process(es) that could not be identified")
+		}
+		return errorf(ErrProtectedProcess, "refusing to kill protected process(es)")
+	}
 
-	if len(portMap) == 0 {
-		fmt.Println("No processes found using the specified ports")
+	selected := selectTargets(ctx, targets, opts)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if len(selected) == 0 {
+		i18n.Println("Operation cancelled")
 		return nil
 	}
 
-	fmt.Println("Processes using the specified ports:")
-	fmt.Println("PORT/PROTOCOL\tPID\tPROCESS")
-	fmt.Println("----------------------------------------")
+	i18n.Println("\nKilling processes...")
+	successCount := 0
+	failCount := 0
+	skipCount := 0
 
-	pidSet := make(map[int]bool)
-	for _, infos := range portMap {
-		for _, info := range infos {
-			fmt.Println(info.String())
-			pidSet[info.PID] = true
+	var denied []string
+	results, killed := killTargets(ctx, manager, selected)
+	for _, result := range results {
+		switch {
+		case result.Skipped != "":
+			i18n.Printf("Skipped process %d: %s\n", result.PID, i18n.T(result.Skipped))
+			skipCount++
+		case result.Killed:
+			i18n.Printf("Successfully killed process %d\n", result.PID)
+			successCount++
+		default:
+			i18n.Printf("Failed to kill process %d: %s\n", result.PID, i18n.Localize(result.err))
+			failCount++
+			if result.PermissionDenied {
+				denied = append(denied, strconv.Itoa(result.PID))
+			}
 		}
 	}
 
-	if !force {
-		fmt.Printf("\nKill these processes? (y/N): ")
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	var stillInUse []heldPort
+	if len(killed) > 0 {
+		stillInUse = verifyReleased(ctx, killed)
+		if len(stillInUse) == 0 {
+			i18n.Println("Verified: all released ports are free")
+		}
+		for _, held := range stillInUse {
+			i18n.Printf("Verification failed: port %s\n", held.Localize())
+		}
+	}
+
+	i18n.Printf("\nSummary: %d succeeded, %d failed, %d skipped\n", successCount, failCount, skipCount)
+
+	if len(denied) > 0 {
+		i18n.Printf("\n%d process(es) could not be killed for lack of privileges", len(denied))
+		if missing := describeMissing(platform.OpKill); missing != "" {
+			i18n.Printf(" (missing %s)", missing)
+		}
+		if hint := elevationHint(); hint != "" {
+			fmt.Printf("; %s", hint)
+		}
+		fmt.Println()
+
+		// 用户已确认过要终止这些进程，提权后直接终止
+		if !opts.Force && !opts.NonInteractive {
+			offerElevation(ctx, opts.Elevate, i18n.T("Retry them with elevated privileges?"),
+				[]string{"release", "--force", "--pid", strings.Join(denied, ",")})
+		}
+	}
+
+	switch {
+	case failCount > 0 && successCount > 0:
+		return errorf(ErrPartialFailure, "failed to kill %d of %d process(es)", failCount, failCount+successCount)
+	case failCount > 0 && len(denied) == failCount:
+		return errorf(ErrPermissionDenied, "failed to kill %d process(es): permission denied", failCount)
+	case failCount > 0:
+		return errorf(ErrPartialFailure, "failed to kill %d process(es)", failCount)
+	case len(stillInUse) > 0:
+		return errorf(ErrPartialFailure, "%d port(s) still in use after release", len(stillInUse))
+	}
+
+	return nil
+}
+
+// killTargets kills every unprotected target, returning one result per target and
+// the targets that were killed
+func killTargets(ctx context.Context, manager platform.PlatformManager, targets []ReleaseTarget) ([]ReleaseResult, []ReleaseTarget) {
+	var results []ReleaseResult
+	var killed []ReleaseTarget
+
+	for _, target := range targets {
+		result := ReleaseResult{PID: target.PID}
+		if target.Protected != "" {
+			result.Skipped = target.Protected
+		} else if err := manager.KillProcessByPID(ctx, target.PID); err != nil {
+			result.Error = err.Error()
+			result.err = err
+			result.PermissionDenied = errors.Is(err, os.ErrPermission)
+		} else {
+			result.Killed = true
+			killed = append(killed, target)
+		}
+		results = append(results, result)
+	}
+
+	return results, killed
+}
+
+// heldPort is a port still in use after its process was killed, or the failure to
+// verify that it was freed
+type heldPort struct {
+	info types.PortInfo
+	// owner is the process now holding the port
+	owner types.PortInfo
+	// restarted is set when another process holds the port, e.g. one restarted by a
+	// supervisor
+	restarted bool
+	err       error
+}
+
+// String describes the port in English, as in ReleaseReport.StillInUse
+func (h heldPort) String() string {
+	return h.describe(fmt.Sprintf, h.err)
+}
+
+// Localize describes the port in the selected language
+func (h heldPort) Localize() string {
+	var err error
+	if h.err != nil {
+		err = errors.New(i18n.Localize(h.err))
+	}
+	return h.describe(i18n.Sprintf, err)
+}
+
+func (h heldPort) describe(sprintf func(string, ...interface{}) string, err error) string {
+	switch {
+	case err != nil:
+		return sprintf("could not be verified: %v", err)
+	case h.restarted:
+		return sprintf("%d/%s is now held by PID %d (%s), possibly restarted by a supervisor",
+			h.info.Port, h.info.Protocol, h.owner.PID, h.owner.ProcessName)
+	}
+	return sprintf("%d/%s is still held by PID %d", h.info.Port, h.info.Protocol, h.owner.PID)
+}
+
+// verifyReleased rescans the system until the ports of the killed processes are free,
+// returning each port still in use when the timeout expires
+func verifyReleased(ctx context.Context, killed []ReleaseTarget) []heldPort {
+	deadline := time.Now().Add(verifyTimeout)
+
+	for {
+		// 每次轮询都重新扫描，不使用缓存的快照
+		manager := platform.GetPlatformManager()
+		platform.Invalidate(manager)
+		sockets, err := manager.GetAllSockets(ctx)
 		if err != nil {
+			return []heldPort{{err: err}}
+		}
+
+		// 按本地地址比较，同一端口上绑定其他地址的套接字不算未释放
+		held := make(map[string]types.PortInfo)
+		for _, socket := range sockets {
+			if platform.IsListening(socket) {
+				held[fmt.Sprintf("%d:%s:%s", socket.Port, socket.Protocol, socket.LocalAddr)] = socket
+			}
+		}
+
+		var remaining []heldPort
+		for _, target := range killed {
+			for _, info := range target.Ports {
+				conn, busy := held[fmt.Sprintf("%d:%s:%s", info.Port, info.Protocol, info.LocalAddr)]
+				if !busy {
+					continue
+				}
+				remaining = append(remaining, heldPort{info: info, owner: conn, restarted: conn.PID != target.PID})
+			}
+		}
+
+		if len(remaining) == 0 || time.Now().After(deadline) {
+			return remaining
+		}
+
+		select {
+		case <-ctx.Done():
+			return remaining
+		case <-time.After(verifyInterval):
+		}
+	}
+}
+
+// groupByProcess groups port records by PID, ordered by PID
+func groupByProcess(ctx context.Context, manager platform.PlatformManager, connections []types.PortInfo) []ReleaseTarget {
+	byPID := make(map[int]*ReleaseTarget)
+	var order []int
+
+	for _, conn := range connections {
+		target, exists := byPID[conn.PID]
+		if !exists {
+			target = &ReleaseTarget{
+				PID:         conn.PID,
+				ProcessName: conn.ProcessName,
+				ProcessPath: conn.ProcessPath,
+				Protected:   protectionReason(conn.PID, conn.ProcessName),
+			}
+			if conn.PID <= 0 && conn.StatusOf(types.FieldPID) == types.StatusPermissionDenied {
+				target.Protected = hiddenOwnerReason
+			}
+			if cmdline, err := manager.GetProcessCommandLine(ctx, conn.PID); err == nil {
+				target.CommandLine = cmdline
+			}
+			byPID[conn.PID] = target
+			order = append(order, conn.PID)
+		}
+		target.Ports = append(target.Ports, conn)
+	}
+
+	sort.Ints(order)
+
+	targets := make([]ReleaseTarget, 0, len(order))
+	for _, pid := range order {
+		target := byPID[pid]
+		types.SortPortInfos(target.Ports)
+		targets = append(targets, *target)
+	}
+
+	return targets
+}
+
+// printTargets prints the numbered list of processes with their command lines and ports
+func printTargets(targets []ReleaseTarget) {
+	for i, target := range targets {
+		fmt.Printf("\n[%d] PID %d  %s\n", i+1, target.PID, target.ProcessName)
+		i18n.Printf("    Command: %s\n", target.displayCommand())
+
+		ports := make([]string, 0, len(target.Ports))
+		for _, info := range target.Ports {
+			if info.Service != "" {
+				ports = append(ports, fmt.Sprintf("%d/%s (%s)", info.Port, info.Protocol, info.Service))
+			} else {
+				ports = append(ports, fmt.Sprintf("%d/%s", info.Port, info.Protocol))
+			}
+		}
+		i18n.Printf("    Ports:   %s\n", strings.Join(ports, ", "))
+		if target.Protected != "" {
+			i18n.Printf("    Protected: %s, will not be killed\n", i18n.T(target.Protected))
+		}
+	}
+}
+
+// printTargetDetails prints everything known about each process and its sockets
+func printTargetDetails(targets []ReleaseTarget) {
+	for i, target := range targets {
+		fmt.Printf("\n[%d] PID %d\n", i+1, target.PID)
+		i18n.Printf("    Name:    %s\n", target.ProcessName)
+		i18n.Printf("    Path:    %s\n", valueOrNA(target.ProcessPath))
+		i18n.Printf("    Command: %s\n", valueOrNA(target.CommandLine))
+		for _, info := range target.Ports {
+			fmt.Printf("    %-12s %-24s %s\n",
+				fmt.Sprintf("%d/%s", info.Port, info.Protocol), info.LocalAddr, info.State)
+		}
+	}
+}
+
+// selectTargets decides which processes to kill according to the release options
+func selectTargets(ctx context.Context, targets []ReleaseTarget, opts ReleaseOptions) []ReleaseTarget {
+	if opts.Force {
+		return targets
+	}
+
+	prompt := opts.Prompt
+	if prompt == nil {
+		prompt = os.Stdin
+	}
+	reader := bufio.NewReader(prompt)
+
+	if opts.NonInteractive || !isTerminal(prompt) {
+		i18n.Printf("\nKill these processes? (y/N): ")
+		response, err := readLine(ctx, reader)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && response == "" {
 			// 如果无法读取输入，默认取消操作
-			fmt.Println("\nOperation cancelled (could not read input)")
+			i18n.Println("\nCould not read input")
 			return nil
 		}
 
 		response = strings.TrimSpace(strings.ToLower(response))
 		if response != "y" && response != "yes" {
-			fmt.Println("Operation cancelled")
 			return nil
 		}
+		return targets
 	}
 
-	fmt.Println("\nKilling processes...")
-	successCount := 0
-	failCount := 0
+	for {
+		i18n.Printf("\nSelect processes to kill [1-%d] (e.g. 1,3-4; a=all, n=none, ?=details): ", len(targets))
+		response, err := readLine(ctx, reader)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			i18n.Println("\nCould not read input")
+			return nil
+		}
 
-	for pid := range pidSet {
-		if err := manager.KillProcessByPID(pid); err != nil {
-			fmt.Printf("Failed to kill process %d: %v\n", pid, err)
-			failCount++
-		} else {
-			fmt.Printf("Successfully killed process %d\n", pid)
-			successCount++
+		response = strings.TrimSpace(strings.ToLower(response))
+		switch response {
+		case "", "n", "no", "none":
+			return nil
+		case "a", "all":
+			return targets
+		case "?":
+			printTargetDetails(targets)
+			continue
+		}
+
+		indexes, err := utils.ParseSelection(response, len(targets))
+		if err != nil {
+			i18n.Printf("Invalid selection: %v\n", err)
+			continue
+		}
+
+		selected := make([]ReleaseTarget, 0, len(indexes))
+		for _, index := range indexes {
+			selected = append(selected, targets[index-1])
 		}
+		return selected
 	}
+}
 
-	fmt.Printf("\nSummary: %d succeeded, %d failed\n", successCount, failCount)
+// readLine reads a line of input, giving up once ctx is done
+func readLine(ctx context.Context, reader *bufio.Reader) (string, error) {
+	type line struct {
+		text string
+		err  error
+	}
+	lines := make(chan line, 1)
+	go func() {
+		text, err := reader.ReadString('\n')
+		lines <- line{text, err}
+	}()
 
-	if failCount > 0 {
-		return fmt.Errorf("failed to kill %d process(es)", failCount)
+	select {
+	case l := <-lines:
+		return l.text, l.err
+	case <-ctx.Done():
+		return "", ctx.Err()
 	}
+}
 
-	return nil
+// displayCommand returns the best available description of how the process was started
+func (t ReleaseTarget) displayCommand() string {
+	if t.CommandLine != "" {
+		return t.CommandLine
+	}
+	return valueOrNA(t.ProcessPath)
+}
+
+// valueOrNA returns the translated "N/A" for empty values
+func valueOrNA(value string) string {
+	if value == "" {
+		return i18n.T("N/A")
+	}
+	return value
+}
+
+// isTerminal reports whether the file is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,334 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReleasePorts(t *testing.T) {
+	for _, host := range fakeHosts {
+		t.Run(host.name, func(t *testing.T) {
+			fake := host.install(t)
+
+			captureStdout(t, func() {
+				if err := ReleasePorts(context.Background(), []string{"8080"}, ReleaseOptions{Force: true}); err != nil {
+					t.Errorf("ReleasePorts: %v", err)
+				}
+			})
+
+			if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+				t.Errorf("killed %v, want [4242]", got)
+			}
+		})
+	}
+}
+
+func TestReleasePortsProtected(t *testing.T) {
+	tests := []struct {
+		host string
+		port string
+	}{
+		{"linux-ss", "2049"},           // 内核持有，没有进程
+		{"darwin-lsof", "22"},          // launchd
+		{"windows-netstat-ano", "445"}, // System
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			var fake interface{ Killed() []int }
+			for _, host := range fakeHosts {
+				if host.name == tt.host {
+					fake = host.install(t)
+				}
+			}
+
+			captureStdout(t, func() {
+				if err := ReleasePorts(context.Background(), []string{tt.port}, ReleaseOptions{Force: true}); ExitCode(err) != ExitProtectedProcess {
+					t.Errorf("ReleasePorts returned %v, want an error for a protected process", err)
+				}
+			})
+
+			if got := fake.Killed(); len(got) != 0 {
+				t.Errorf("killed %v, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestReleasePortsKillFailure(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.KillErrors = map[int]error{4242: fmt.Errorf("operation not permitted")}
+
+	captureStdout(t, func() {
+		if err := ReleasePorts(context.Background(), []string{"8080"}, ReleaseOptions{Force: true}); !errors.Is(err, ErrPartialFailure) {
+			t.Errorf("ReleasePorts returned %v, want ErrPartialFailure", err)
+		}
+	})
+
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}
+
+func TestReleasePortsNothingListening(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+
+	output := captureStdout(t, func() {
+		if err := ReleasePorts(context.Background(), []string{"9"}, ReleaseOptions{Force: true}); !errors.Is(err, ErrNoMatch) {
+			t.Errorf("ReleasePorts returned %v, want ErrNoMatch", err)
+		}
+	})
+
+	if output != "No processes found using the specified ports\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}
+
+func TestReleaseProcesses(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+
+	captureStdout(t, func() {
+		if err := ReleaseProcesses(context.Background(), ProcessFilter{Names: []string{"python3"}}, ReleaseOptions{Force: true}); err != nil {
+			t.Errorf("ReleaseProcesses: %v", err)
+		}
+	})
+
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+		t.Errorf("killed %v, want [4242]", got)
+	}
+}
+
+func TestReleaseProcessesSharedSocket(t *testing.T) {
+	// nginx 的 master 和 worker 共享 80 端口的监听套接字
+	fake := fakeHosts[0].install(t)
+
+	captureStdout(t, func() {
+		if err := ReleaseProcesses(context.Background(), ProcessFilter{PIDs: []int{1200, 1201}}, ReleaseOptions{Force: true}); err != nil {
+			t.Errorf("ReleaseProcesses: %v", err)
+		}
+	})
+
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{1200, 1201}) {
+		t.Errorf("killed %v, want [1200 1201]", got)
+	}
+}
+
+func TestReleasePortsPermissionDenied(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.KillErrors = map[int]error{4242: fmt.Errorf("kill: %w", os.ErrPermission)}
+
+	output := captureStdout(t, func() {
+		if err := ReleasePorts(context.Background(), []string{"8080"}, ReleaseOptions{Force: true}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("ReleasePorts returned %v, want ErrPermissionDenied", err)
+		}
+	})
+
+	if !strings.Contains(output, "1 process(es) could not be killed for lack of privileges") {
+		t.Errorf("output does not explain the missing privileges:\n%s", output)
+	}
+}
+
+func TestReleasePortsHiddenOwner(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Unprivileged = true
+
+	output := captureStdout(t, func() {
+		if err := ReleasePorts(context.Background(), []string{"2049"}, ReleaseOptions{Force: true}); !errors.Is(err, ErrPermissionDenied) {
+			t.Errorf("ReleasePorts returned %v, want ErrPermissionDenied for a hidden owner", err)
+		}
+	})
+
+	if !strings.Contains(output, hiddenOwnerReason) || !strings.Contains(output, "is hidden for lack of privileges") {
+		t.Errorf("output does not explain the hidden owner:\n%s", output)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}
+
+func TestReleaseInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		list   string
+		killed []int
+		code   int
+	}{
+		{"json", `[{"port": 8080, "protocol": "TCP", "pid": 4242, "process_name": "python3", "local_addr": "0.0.0.0:8080"}]`, StdinPorts, []int{4242}, ExitOK},
+		{"ndjson", "{\"port\": 8080, \"protocol\": \"TCP\", \"pid\": 4242}\n{\"port\": 9, \"protocol\": \"TCP\", \"pid\": 4242}\n", StdinPorts, []int{4242}, ExitOK},
+		{"grouped", `{"group_by": "process", "groups": [{"key": "4242", "ports": [{"port": 8080, "protocol": "TCP", "pid": 4242}]}]}`, StdinPorts, []int{4242}, ExitOK},
+		{"other owner", `[{"port": 8080, "protocol": "TCP", "pid": 5000}]`, StdinPorts, nil, ExitNoMatch},
+		{"reused PID", `[{"port": 8080, "protocol": "TCP", "pid": 4242, "process_name": "node"}]`, StdinPorts, nil, ExitNoMatch},
+		{"moved address", `[{"port": 8080, "protocol": "TCP", "pid": 4242, "local_addr": "127.0.0.1:8080"}]`, StdinPorts, nil, ExitNoMatch},
+		{"IPv6 listener", `[{"port": 22, "protocol": "TCP", "pid": 1001, "local_addr": "[::]:22"}]`, StdinPorts, []int{1001}, ExitOK},
+		{"shared socket", `[{"port": 80, "protocol": "TCP", "pid": 1200}, {"port": 80, "protocol": "TCP", "pid": 1201}]`, StdinPorts, []int{1200, 1201}, ExitOK},
+		{"no PID", `[{"port": 8080, "protocol": "TCP"}]`, StdinPorts, nil, ExitNoMatch},
+		{"no PID with address", `[{"port": 8080, "protocol": "TCP", "pid": 0, "local_addr": "0.0.0.0:8080"}]`, StdinPorts, []int{4242}, ExitOK},
+		{"no PID on shared socket", `[{"port": 80, "protocol": "TCP", "local_addr": "0.0.0.0:80"}]`, StdinPorts, nil, ExitNoMatch},
+		{"PIDs of shared sockets", "1200 1201", StdinPIDs, []int{1200, 1201}, ExitOK},
+		{"ports", "8080\n", StdinPorts, []int{4242}, ExitOK},
+		{"PIDs", "4242, 5000", StdinPIDs, []int{4242}, ExitOK},
+		{"numbers", `[4242]`, StdinPIDs, []int{4242}, ExitOK},
+		{"invalid JSON", `{"port": 8080`, StdinPorts, nil, ExitInvalidPattern},
+		{"invalid PID", "python3", StdinPIDs, nil, ExitInvalidPattern},
+		{"empty", "\n", StdinPorts, nil, ExitInvalidPattern},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeHosts[0].install(t)
+
+			captureStdout(t, func() {
+				err := ReleaseInput(context.Background(), strings.NewReader(tt.input), tt.list, ReleaseOptions{Force: true})
+				if got := ExitCode(err); got != tt.code {
+					t.Errorf("ReleaseInput returned %v (exit code %d), want exit code %d", err, got, tt.code)
+				}
+			})
+
+			if got := fake.Killed(); !reflect.DeepEqual(got, tt.killed) {
+				t.Errorf("killed %v, want %v", got, tt.killed)
+			}
+		})
+	}
+}
+
+func TestReleaseInputWithoutPID(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+
+	// 没有 --force 时不按地址释放没有 PID 的记录
+	output := captureStdout(t, func() {
+		input := `[{"port": 8080, "protocol": "TCP", "local_addr": "0.0.0.0:8080"}]`
+		err := ReleaseInput(context.Background(), strings.NewReader(input), StdinPorts, ReleaseOptions{})
+		if got := ExitCode(err); got != ExitNoMatch {
+			t.Errorf("ReleaseInput returned %v (exit code %d), want exit code %d", err, got, ExitNoMatch)
+		}
+	})
+	if !strings.Contains(output, "no PID recorded") {
+		t.Errorf("output lacks the reason:\n%s", output)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}
+
+func TestReleaseInputSharedWithoutPID(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+
+	// nginx 1200 和 1201 共享 0.0.0.0:80，没有 PID 时即使 --force 也不能任选其一
+	output := captureStdout(t, func() {
+		input := `[{"port": 80, "protocol": "TCP", "local_addr": "0.0.0.0:80"}]`
+		err := ReleaseInput(context.Background(), strings.NewReader(input), StdinPorts, ReleaseOptions{Force: true})
+		if got := ExitCode(err); got != ExitNoMatch {
+			t.Errorf("ReleaseInput returned %v (exit code %d), want exit code %d", err, got, ExitNoMatch)
+		}
+	})
+	if !strings.Contains(output, "held by several processes (PID 1200, 1201)") {
+		t.Errorf("output lacks the reason:\n%s", output)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}
+
+func TestReleaseInputFromCheck(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+
+	output := captureStdout(t, func() {
+		opts := CheckOptions{Output: OutputJSON, Processes: []string{"python3"}, Users: []string{"alice"}}
+		if err := CheckPorts(context.Background(), nil, opts); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	captureStdout(t, func() {
+		if err := ReleaseInput(context.Background(), strings.NewReader(output), StdinPorts, ReleaseOptions{Force: true}); err != nil {
+			t.Errorf("ReleaseInput: %v", err)
+		}
+	})
+
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+		t.Errorf("killed %v, want [4242]", got)
+	}
+}
+
+func TestReleasePortsQualified(t *testing.T) {
+	tests := []struct {
+		host   string
+		target string
+		killed []int
+		code   int
+	}{
+		{"linux-ss", "53/udp", []int{612}, ExitOK},
+		{"linux-ss", "53/tcp", nil, ExitNoMatch},
+		{"linux-ss", "127.0.0.53:53", []int{612}, ExitOK},
+		{"linux-ss", "0.0.0.0:8080/tcp", []int{4242}, ExitOK},
+		{"linux-ss", "127.0.0.1:8080", nil, ExitNoMatch},
+		{"linux-ss", "[::]:22", []int{1001}, ExitOK},
+		{"linux-ss", "http", []int{1200, 1201}, ExitOK},
+		{"darwin-lsof", "127.0.0.1:8080", []int{4242}, ExitOK},
+		{"darwin-lsof", "[::1]:8080", nil, ExitNoMatch},
+		{"windows-netstat-ano", "0.0.0.0:8080/tcp", []int{4242}, ExitOK},
+		{"linux-ss", "::1:8080", nil, ExitInvalidPattern},
+		{"linux-ss", "8080/sctp", nil, ExitInvalidPattern},
+		{"linux-ss", "no-such-interface0:8080", nil, ExitInvalidPattern},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host+" "+tt.target, func(t *testing.T) {
+			var fake interface{ Killed() []int }
+			for _, host := range fakeHosts {
+				if host.name == tt.host {
+					fake = host.install(t)
+				}
+			}
+
+			captureStdout(t, func() {
+				err := ReleasePorts(context.Background(), []string{tt.target}, ReleaseOptions{Force: true})
+				if got := ExitCode(err); got != tt.code {
+					t.Errorf("ReleasePorts returned %v (exit code %d), want exit code %d", err, got, tt.code)
+				}
+			})
+
+			if got := fake.Killed(); !reflect.DeepEqual(got, tt.killed) {
+				t.Errorf("killed %v, want %v", got, tt.killed)
+			}
+		})
+	}
+}
+
+func TestReleasePortsInterface(t *testing.T) {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		t.Skip(err)
+	}
+	var loopback string
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			loopback = iface.Name
+		}
+	}
+	if loopback == "" {
+		t.Skip("no loopback interface")
+	}
+
+	// darwin 的 Python 监听 127.0.0.1:8080，属于回环网卡
+	fake := fakeHosts[2].install(t)
+	captureStdout(t, func() {
+		if err := ReleasePorts(context.Background(), []string{loopback + ":8080"}, ReleaseOptions{Force: true}); err != nil {
+			t.Errorf("ReleasePorts: %v", err)
+		}
+	})
+
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+		t.Errorf("killed %v, want [4242]", got)
+	}
+}
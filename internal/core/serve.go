@@ -0,0 +1,406 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/types"
+)
+
+// ServeOptions controls the HTTP API server
+type ServeOptions struct {
+	// Listen is the address to listen on, e.g. 127.0.0.1:9876
+	Listen string
+	// Token is the bearer token required on every request. Without one, only loopback
+	// addresses may be listened on and POST /release is disabled.
+	Token string
+	// Interval is how often the event stream rescans the ports
+	Interval time.Duration
+}
+
+// releaseRequest is the body of POST /release
+type releaseRequest struct {
+	Ports []string `json:"ports"`
+	ProcessFilter
+	DryRun bool `json:"dry_run"`
+}
+
+// portEvent is one change sent on the event stream
+type portEvent struct {
+	Type   string          `json:"type"`
+	Port   types.PortInfo  `json:"port"`
+	Before *types.PortInfo `json:"before,omitempty"`
+}
+
+// Serve runs the HTTP API until interrupted
+func Serve(ctx context.Context, opts ServeOptions) error {
+	if opts.Interval <= 0 {
+		return errorf(ErrInvalidPattern, "interval must be positive, got %s", opts.Interval)
+	}
+	if opts.Token == "" && !isLoopbackAddr(opts.Listen) {
+		return errorf(ErrInvalidPattern, "refusing to listen on non-loopback address %s without a token", opts.Listen)
+	}
+
+	warning := ""
+	if opts.Token == "" {
+		warning = i18n.T("Warning: no token set, POST /release is disabled")
+	}
+	return listenAndServe(ctx, opts.Listen, newAPIHandler(opts), "API", warning)
+}
+
+// newAPIHandler returns the routes of the API server behind its host and token checks
+func newAPIHandler(opts ServeOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ports", handlePorts)
+	mux.HandleFunc("/ports/", handlePort)
+	mux.HandleFunc("/release", func(w http.ResponseWriter, r *http.Request) {
+		// 没有令牌时任何本地进程和网页都能访问回环地址，不允许释放端口
+		if opts.Token == "" {
+			writeError(w, http.StatusForbidden, fmt.Errorf("release is disabled without a token, start serve with --token"))
+			return
+		}
+		handleRelease(w, r)
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		handleEvents(w, r, opts.Interval)
+	})
+	mux.Handle("/metrics", newMetricsHandler())
+
+	return checkHost(opts.Listen, requireToken(opts.Token, mux))
+}
+
+// listenAndServe serves the handler until ctx is done, printing the address and an
+// optional, already translated warning once listening
+func listenAndServe(ctx context.Context, listen string, handler http.Handler, name string, warning string) error {
+	server := &http.Server{
+		Addr:    listen,
+		Handler: handler,
+		// 事件流和后端命令在收到中断信号时随请求上下文一起结束
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	i18n.Printf("Serving %s on http://%s\n", name, listener.Addr())
+	if warning != "" {
+		fmt.Println(warning)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// handlePorts serves GET /ports with the same filters as check
+func handlePorts(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	query := r.URL.Query()
+	patterns := queryPatterns(query)
+	opts := CheckOptions{
+		Wildcard: queryBool(query, "wildcard"),
+		GroupBy:  query.Get("group_by"),
+		Sort:     query.Get("sort"),
+		Reverse:  queryBool(query, "reverse"),
+	}
+
+	ports, err := QueryPorts(r.Context(), patterns, opts, true)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	if ports == nil {
+		ports = []types.PortInfo{}
+	}
+
+	if opts.GroupBy != "" {
+		writeJSON(w, http.StatusOK, groupedOutput{GroupBy: opts.GroupBy, Total: len(ports), Groups: groupPorts(ports, opts.GroupBy)})
+		return
+	}
+	writeJSON(w, http.StatusOK, ports)
+}
+
+// handlePort serves GET /ports/{port}, where port is a port number or service name
+func handlePort(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	pattern := strings.TrimPrefix(r.URL.Path, "/ports/")
+	if pattern == "" || strings.Contains(pattern, "/") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("not found"))
+		return
+	}
+
+	ports, err := QueryPorts(r.Context(), []string{pattern}, CheckOptions{}, true)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	if len(ports) == 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("port %s is not in use", pattern))
+		return
+	}
+	writeJSON(w, http.StatusOK, ports)
+}
+
+// handleRelease serves POST /release, returning the plan and, unless dry_run is set, the results
+func handleRelease(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	// 只接受 JSON 请求体，网页无需预检即可发送的表单请求会被拒绝
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("request body must be application/json"))
+		return
+	}
+
+	var req releaseRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+		return
+	}
+
+	targets, err := PlanRelease(r.Context(), req.Ports, req.ProcessFilter)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	if targets == nil {
+		targets = []ReleaseTarget{}
+	}
+
+	if req.DryRun {
+		writeJSON(w, http.StatusOK, ReleaseReport{DryRun: true, Targets: targets})
+		return
+	}
+	writeJSON(w, http.StatusOK, ExecuteRelease(r.Context(), targets))
+}
+
+// handleEvents serves GET /events, a Server-Sent Events stream of opened, closed and
+// changed ports. The first event is a snapshot of the current state.
+func handleEvents(w http.ResponseWriter, r *http.Request, interval time.Duration) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+
+	patterns := queryPatterns(r.URL.Query())
+	previous, err := QueryPorts(r.Context(), patterns, CheckOptions{}, false)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+	if previous == nil {
+		previous = []types.PortInfo{}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(w, "snapshot", previous)
+	flusher.Flush()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := QueryPorts(r.Context(), patterns, CheckOptions{}, false)
+		if err != nil {
+			writeEvent(w, "error", map[string]string{"error": err.Error()})
+			flusher.Flush()
+			continue
+		}
+
+		diff := diffPortStates(previous, current)
+		for _, info := range diff.Opened {
+			writeEvent(w, "opened", portEvent{Type: "opened", Port: info})
+		}
+		for _, info := range diff.Closed {
+			writeEvent(w, "closed", portEvent{Type: "closed", Port: info})
+		}
+		for _, change := range diff.Changed {
+			before := change.Before
+			writeEvent(w, "changed", portEvent{Type: "changed", Port: change.After, Before: &before})
+		}
+		flusher.Flush()
+
+		previous = current
+	}
+}
+
+// requireToken rejects requests without the bearer token, if one is configured
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		given, found := strings.CutPrefix(auth, "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="portreleasor"`)
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkHost rejects requests to a loopback listener whose Host or Origin header names
+// another host, so web pages cannot reach the API through DNS rebinding or cross-site
+// requests. Other listeners require a token, which web pages cannot send.
+func checkHost(listen string, next http.Handler) http.Handler {
+	if !isLoopbackAddr(listen) {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || !isLoopbackHost(u.Host) {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %q not allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorStatus returns the HTTP status of an error returned by the commands
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrInvalidPattern):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrNoMatch):
+		return http.StatusNotFound
+	case errors.Is(err, ErrPermissionDenied):
+		return http.StatusForbidden
+	case errors.Is(err, ErrTimeout):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrBackendUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// allowMethod replies 405 and returns false if the request does not use the given method
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeEvent writes one Server-Sent Event with a JSON payload
+func writeEvent(w http.ResponseWriter, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// queryBool parses a boolean query parameter, treating a bare "?flag" as true
+func queryBool(query url.Values, key string) bool {
+	if !query.Has(key) {
+		return false
+	}
+	value := query.Get(key)
+	if value == "" {
+		return true
+	}
+	b, err := strconv.ParseBool(value)
+	return err == nil && b
+}
+
+// queryPatterns returns the port patterns of the pattern query parameters, which may be
+// repeated or list several patterns separated by commas
+func queryPatterns(query url.Values) []string {
+	var patterns []string
+	for _, value := range query["pattern"] {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && isLoopbackHost(host)
+}
+
+// isLoopbackHost reports whether a host, with or without port, is localhost or a
+// loopback IP address
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIHandler(t *testing.T) {
+	const token = "s3cret"
+
+	for _, tt := range []struct {
+		name    string
+		token   string
+		method  string
+		target  string
+		host    string
+		headers map[string]string
+		body    string
+		want    int
+	}{
+		{name: "list", method: http.MethodGet, target: "/ports", want: http.StatusOK},
+		{name: "invalid sort", method: http.MethodGet, target: "/ports?sort=size", want: http.StatusBadRequest},
+		{name: "port", method: http.MethodGet, target: "/ports/8080", want: http.StatusOK},
+		{name: "port not in use", method: http.MethodGet, target: "/ports/9", want: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, target: "/ports", want: http.StatusMethodNotAllowed},
+		{name: "release without token", method: http.MethodPost, target: "/release",
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `{"ports":["8080"],"dry_run":true}`, want: http.StatusForbidden},
+		{name: "missing bearer", token: token, method: http.MethodPost, target: "/release",
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    `{"ports":["8080"],"dry_run":true}`, want: http.StatusUnauthorized},
+		{name: "form body", token: token, method: http.MethodPost, target: "/release",
+			headers: map[string]string{"Authorization": "Bearer " + token, "Content-Type": "application/x-www-form-urlencoded"},
+			body:    `{"ports":["8080"],"dry_run":true}`, want: http.StatusUnsupportedMediaType},
+		{name: "invalid port", token: token, method: http.MethodPost, target: "/release",
+			headers: map[string]string{"Authorization": "Bearer " + token, "Content-Type": "application/json"},
+			body:    `{"ports":["99999"],"dry_run":true}`, want: http.StatusBadRequest},
+		{name: "dry run", token: token, method: http.MethodPost, target: "/release",
+			headers: map[string]string{"Authorization": "Bearer " + token, "Content-Type": "application/json; charset=utf-8"},
+			body:    `{"ports":["8080"],"dry_run":true}`, want: http.StatusOK},
+		{name: "rebound host", method: http.MethodGet, target: "/ports", host: "evil.example:9876", want: http.StatusForbidden},
+		{name: "cross-site origin", method: http.MethodGet, target: "/ports",
+			headers: map[string]string{"Origin": "http://evil.example"}, want: http.StatusForbidden},
+		{name: "localhost origin", method: http.MethodGet, target: "/ports",
+			headers: map[string]string{"Origin": "http://localhost:3000"}, want: http.StatusOK},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakeHosts[0].install(t)
+			handler := newAPIHandler(ServeOptions{Listen: "127.0.0.1:9876", Token: tt.token, Interval: time.Second})
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Host = "localhost:9876"
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.target, rec.Code, tt.want, rec.Body)
+			}
+			if got := fake.Killed(); len(got) != 0 {
+				t.Errorf("killed %v, want nothing", got)
+			}
+		})
+	}
+}
+
+func TestAPIHandlerDryRun(t *testing.T) {
+	fakeHosts[0].install(t)
+	handler := newAPIHandler(ServeOptions{Listen: "127.0.0.1:9876", Token: "s3cret", Interval: time.Second})
+
+	req := httptest.NewRequest(http.MethodPost, "/release", strings.NewReader(`{"ports":["8080"],"dry_run":true}`))
+	req.Host = "localhost:9876"
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /release = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var report ReleaseReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON response: %v\n%s", err, rec.Body)
+	}
+	if !report.DryRun || len(report.Targets) != 1 || report.Targets[0].PID != 4242 || len(report.Results) != 0 {
+		t.Errorf("report = %+v, want a dry run of PID 4242", report)
+	}
+}
+
+func TestAPIHandlerBackendFailure(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	fake.Format = "unknown"
+	handler := newAPIHandler(ServeOptions{Listen: "127.0.0.1:9876", Interval: time.Second})
+
+	for _, target := range []string{"/ports", "/ports/8080"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "localhost:9876"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("GET %s = %d, want %d: %s", target, rec.Code, http.StatusServiceUnavailable, rec.Body)
+		}
+	}
+}
+
+func TestQueryPatterns(t *testing.T) {
+	for query, want := range map[string][]string{
+		"":                             nil,
+		"pattern=80":                   {"80"},
+		"pattern=80,443":               {"80", "443"},
+		"pattern=80&pattern=8000-8002": {"80", "8000-8002"},
+		"pattern=80,%20443,&pattern=":  {"80", "443"},
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := queryPatterns(values); !reflect.DeepEqual(got, want) {
+			t.Errorf("queryPatterns(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"localhost":        true,
+		"LOCALHOST:9876":   true,
+		"127.0.0.1":        true,
+		"127.0.0.2:80":     true,
+		"[::1]:9876":       true,
+		"[::1]":            true,
+		"10.0.0.5:9876":    false,
+		"evil.example":     false,
+		"localhost.evil":   false,
+		"":                 false,
+		"[::ffff:1.2.3.4]": false,
+	} {
+		if got := isLoopbackHost(host); got != want {
+			t.Errorf("isLoopbackHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
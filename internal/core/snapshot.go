@@ -0,0 +1,282 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+)
+
+// snapshotVersion is the version of the snapshot file format; version 2 records the
+// filter and every listening socket instead of one per port and protocol
+const snapshotVersion = 2
+
+// Snapshot is the port state saved by check --save
+type Snapshot struct {
+	Version   int              `json:"version"`
+	Host      string           `json:"host"`
+	CreatedAt time.Time        `json:"created_at"`
+	Filter    *SnapshotFilter  `json:"filter,omitempty"`
+	Ports     []types.PortInfo `json:"ports"`
+}
+
+// SnapshotFilter is the selection of check a snapshot was saved with, applied to the
+// live state as well when diffing against it
+type SnapshotFilter struct {
+	Patterns  []string `json:"patterns,omitempty"`
+	Wildcard  bool     `json:"wildcard,omitempty"`
+	Processes []string `json:"processes,omitempty"`
+	Users     []string `json:"users,omitempty"`
+}
+
+// DiffOptions controls how DiffPorts reports differences
+type DiffOptions struct {
+	// Output is the output format, OutputTable or OutputJSON
+	Output string
+	// ExitCode makes DiffPorts return ErrDifferences when the states differ
+	ExitCode bool
+}
+
+// portChange is a port whose owner changed between two snapshots
+type portChange struct {
+	Before types.PortInfo `json:"before"`
+	After  types.PortInfo `json:"after"`
+}
+
+// portDiff is the difference between two port states
+type portDiff struct {
+	Before  string           `json:"before"`
+	After   string           `json:"after"`
+	Opened  []types.PortInfo `json:"opened"`
+	Closed  []types.PortInfo `json:"closed"`
+	Changed []portChange     `json:"changed"`
+}
+
+// saveSnapshot writes the listening sockets selected by the filter to a snapshot
+// file, returning how many were saved
+func saveSnapshot(ctx context.Context, manager platform.PlatformManager, path string, filter SnapshotFilter) (int, error) {
+	ports, err := snapshotPorts(ctx, manager, &filter)
+	if err != nil {
+		return 0, err
+	}
+
+	host, _ := os.Hostname()
+	if ports == nil {
+		ports = []types.PortInfo{}
+	}
+
+	snapshot := Snapshot{
+		Version:   snapshotVersion,
+		Host:      host,
+		CreatedAt: time.Now(),
+		Ports:     ports,
+	}
+	if !filter.IsEmpty() {
+		snapshot.Filter = &filter
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return 0, i18n.Errorf("failed to encode snapshot: %v", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return 0, i18n.Errorf("failed to save snapshot: %v", err)
+	}
+	return len(ports), nil
+}
+
+// IsEmpty reports whether the filter selects every listening socket
+func (f SnapshotFilter) IsEmpty() bool {
+	return len(f.Patterns) == 0 && len(f.Processes) == 0 && len(f.Users) == 0
+}
+
+// snapshotPorts returns every listening socket selected by the filter, nil for all,
+// with the process details check shows in JSON
+func snapshotPorts(ctx context.Context, manager platform.PlatformManager, filter *SnapshotFilter) ([]types.PortInfo, error) {
+	if filter == nil {
+		filter = &SnapshotFilter{}
+	}
+
+	// 快照记录所有监听套接字，同一端口上的 IPv4、IPv6 监听者都要比较
+	ports, err := listeningSockets(ctx, manager)
+	if err != nil {
+		return nil, err
+	}
+
+	ports = matchPatterns(ports, filter.Patterns, filter.Wildcard)
+	if len(filter.Processes) > 0 {
+		ports = matchProcesses(ports, ProcessFilter{Names: filter.Processes})
+	}
+	annotateServices(ports)
+	enrichProcessDetails(ctx, manager, ports)
+	if len(filter.Users) > 0 {
+		ports = matchUsers(ports, filter.Users)
+	}
+	return ports, nil
+}
+
+// loadSnapshot reads a snapshot file written by check --save
+func loadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("failed to read snapshot: %v", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, i18n.Errorf("invalid snapshot %s: %v", path, err)
+	}
+	if snapshot.Version > snapshotVersion {
+		return nil, i18n.Errorf("snapshot %s has unsupported version %d", path, snapshot.Version)
+	}
+
+	return &snapshot, nil
+}
+
+// DiffPorts compares a saved snapshot with another snapshot, or with the live
+// port state when afterPath is empty, and reports opened, closed and re-owned ports
+func DiffPorts(ctx context.Context, beforePath string, afterPath string, opts DiffOptions) error {
+	if err := validateOutput(opts.Output); err != nil {
+		return err
+	}
+
+	before, err := loadSnapshot(beforePath)
+	if err != nil {
+		return err
+	}
+
+	var after []types.PortInfo
+	afterName := "live state"
+	if afterPath != "" {
+		snapshot, err := loadSnapshot(afterPath)
+		if err != nil {
+			return err
+		}
+		after = snapshot.Ports
+		afterName = afterPath
+	} else {
+		manager := platform.GetPlatformManager()
+		if manager == nil {
+			return errorf(ErrBackendUnavailable, "unsupported platform")
+		}
+		// 按保存快照时的条件筛选实时状态，否则未保存的端口都会显示为新开放
+		if after, err = snapshotPorts(ctx, manager, before.Filter); err != nil {
+			return err
+		}
+	}
+
+	diff := diffPortStates(before.Ports, after)
+	diff.Before = beforePath
+	diff.After = afterName
+
+	if opts.Output == OutputJSON {
+		if err := printJSON(diff); err != nil {
+			return err
+		}
+	} else {
+		printDiff(diff, before)
+	}
+
+	if opts.ExitCode && len(diff.Opened)+len(diff.Closed)+len(diff.Changed) > 0 {
+		return ErrDifferences
+	}
+	return nil
+}
+
+// diffPortStates compares two port lists keyed by port, protocol and local address
+func diffPortStates(before, after []types.PortInfo) portDiff {
+	diff := portDiff{
+		Opened:  []types.PortInfo{},
+		Closed:  []types.PortInfo{},
+		Changed: []portChange{},
+	}
+
+	beforeByKey := socketsByKey(before)
+	afterByKey := socketsByKey(after)
+
+	for key, info := range afterByKey {
+		old, existed := beforeByKey[key]
+		switch {
+		case !existed:
+			diff.Opened = append(diff.Opened, info)
+		case old.PID != info.PID || old.ProcessName != info.ProcessName:
+			diff.Changed = append(diff.Changed, portChange{Before: old, After: info})
+		}
+	}
+	for key, info := range beforeByKey {
+		if _, exists := afterByKey[key]; !exists {
+			diff.Closed = append(diff.Closed, info)
+		}
+	}
+
+	types.SortPortInfos(diff.Opened)
+	types.SortPortInfos(diff.Closed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].After.Less(diff.Changed[j].After)
+	})
+
+	return diff
+}
+
+// snapshotKey identifies a listening socket across snapshots
+func snapshotKey(info types.PortInfo) string {
+	return fmt.Sprintf("%d/%s/%s", info.Port, info.Protocol, info.LocalAddr)
+}
+
+// socketsByKey indexes the sockets by snapshotKey; of the processes sharing a socket,
+// such as nginx workers, the one with the lowest PID is kept so both sides agree
+func socketsByKey(ports []types.PortInfo) map[string]types.PortInfo {
+	byKey := make(map[string]types.PortInfo)
+	for _, info := range ports {
+		key := snapshotKey(info)
+		if existing, exists := byKey[key]; !exists || info.PID < existing.PID {
+			byKey[key] = info
+		}
+	}
+	return byKey
+}
+
+// printDiff prints the difference in a human readable form
+func printDiff(diff portDiff, before *Snapshot) {
+	after := diff.After
+	if after == "live state" {
+		after = i18n.T("live state")
+	}
+	i18n.Printf("Comparing %s (%s) with %s\n\n",
+		diff.Before, before.CreatedAt.Local().Format(time.DateTime), after)
+
+	if len(diff.Opened)+len(diff.Closed)+len(diff.Changed) == 0 {
+		i18n.Println("No differences")
+		return
+	}
+
+	for _, info := range diff.Opened {
+		i18n.Printf("+ %-12s %-24s opened by %s\n", portLabel(info), info.LocalAddr, ownerLabel(info))
+	}
+	for _, info := range diff.Closed {
+		i18n.Printf("- %-12s %-24s closed (was %s)\n", portLabel(info), info.LocalAddr, ownerLabel(info))
+	}
+	for _, change := range diff.Changed {
+		i18n.Printf("~ %-12s %-24s owner changed: %s -> %s\n", portLabel(change.After), change.After.LocalAddr,
+			ownerLabel(change.Before), ownerLabel(change.After))
+	}
+
+	i18n.Printf("\nSummary: %d opened, %d closed, %d changed\n", len(diff.Opened), len(diff.Closed), len(diff.Changed))
+}
+
+// portLabel returns "port/protocol"
+func portLabel(info types.PortInfo) string {
+	return fmt.Sprintf("%d/%s", info.Port, info.Protocol)
+}
+
+// ownerLabel returns "name (PID n)" in the selected language
+func ownerLabel(info types.PortInfo) string {
+	return fmt.Sprintf("%s (PID %d)", valueOrNA(fieldText(info, types.FieldProcessName, info.ProcessName)), info.PID)
+}
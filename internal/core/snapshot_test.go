@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"portreleasor/internal/types"
+)
+
+func TestDiffPortsFiltered(t *testing.T) {
+	fake := fakeHosts[0].install(t)
+	path := filepath.Join(t.TempDir(), "before.json")
+
+	captureStdout(t, func() {
+		if err := CheckPorts(context.Background(), nil, CheckOptions{Output: OutputJSON, Save: path, Processes: []string{"sshd"}}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	snapshot, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Filter == nil || len(snapshot.Filter.Processes) != 1 {
+		t.Errorf("snapshot filter = %+v, want the sshd process", snapshot.Filter)
+	}
+	// sshd 在 IPv4 和 IPv6 上都监听 22 端口
+	if len(snapshot.Ports) != 2 {
+		t.Fatalf("snapshot has %d ports, want 2: %+v", len(snapshot.Ports), snapshot.Ports)
+	}
+
+	diff := diffLive(t, path)
+	if len(diff.Opened)+len(diff.Closed)+len(diff.Changed) != 0 {
+		t.Errorf("unchanged state differs from the filtered snapshot: %+v", diff)
+	}
+
+	if err := fake.KillProcessByPID(context.Background(), 1001); err != nil {
+		t.Fatal(err)
+	}
+	diff = diffLive(t, path)
+	if len(diff.Closed) != 2 || len(diff.Opened) != 0 {
+		t.Errorf("got %d closed and %d opened ports after killing sshd, want 2 and 0", len(diff.Closed), len(diff.Opened))
+	}
+
+	captureStdout(t, func() {
+		err := DiffPorts(context.Background(), path, "", DiffOptions{Output: OutputJSON, ExitCode: true})
+		if !errors.Is(err, ErrDifferences) || ExitCode(err) != ExitDifferences {
+			t.Errorf("DiffPorts returned %v (exit %d), want ErrDifferences (exit %d)", err, ExitCode(err), ExitDifferences)
+		}
+	})
+}
+
+func TestDiffPortStates(t *testing.T) {
+	before := []types.PortInfo{
+		{Port: 22, Protocol: "TCP", LocalAddr: "0.0.0.0:22", PID: 1001, ProcessName: "sshd"},
+		{Port: 80, Protocol: "TCP", LocalAddr: "0.0.0.0:80", PID: 1201, ProcessName: "nginx"},
+		{Port: 80, Protocol: "TCP", LocalAddr: "0.0.0.0:80", PID: 1200, ProcessName: "nginx"},
+		{Port: 8080, Protocol: "TCP", LocalAddr: "0.0.0.0:8080", PID: 4242, ProcessName: "python3"},
+	}
+	after := []types.PortInfo{
+		{Port: 22, Protocol: "TCP", LocalAddr: "0.0.0.0:22", PID: 1001, ProcessName: "sshd"},
+		{Port: 22, Protocol: "TCP", LocalAddr: "[::]:22", PID: 1001, ProcessName: "sshd"},
+		{Port: 80, Protocol: "TCP", LocalAddr: "0.0.0.0:80", PID: 1200, ProcessName: "nginx"},
+		{Port: 80, Protocol: "TCP", LocalAddr: "0.0.0.0:80", PID: 1201, ProcessName: "nginx"},
+		{Port: 8080, Protocol: "TCP", LocalAddr: "0.0.0.0:8080", PID: 5000, ProcessName: "java"},
+		{Port: 53, Protocol: "UDP", LocalAddr: "127.0.0.53%lo:53", PID: 612, ProcessName: "systemd-resolve"},
+	}
+
+	diff := diffPortStates(before, after)
+
+	if len(diff.Opened) != 2 || diff.Opened[0].Port != 22 || diff.Opened[1].Port != 53 {
+		t.Errorf("opened = %+v, want [::]:22 and 53/UDP", diff.Opened)
+	}
+	if len(diff.Closed) != 0 {
+		t.Errorf("closed = %+v, want none", diff.Closed)
+	}
+	// nginx 的 worker 顺序不同不算变化
+	if len(diff.Changed) != 1 || diff.Changed[0].Before.PID != 4242 || diff.Changed[0].After.PID != 5000 {
+		t.Errorf("changed = %+v, want 8080 taken over by PID 5000", diff.Changed)
+	}
+}
+
+func TestLoadSnapshotVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "ports": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshot(path); err == nil {
+		t.Error("loadSnapshot accepted an unsupported version")
+	}
+}
+
+// diffLive compares the snapshot with the live state and returns the JSON diff
+func diffLive(t *testing.T, path string) portDiff {
+	t.Helper()
+
+	var diff portDiff
+	output := captureStdout(t, func() {
+		if err := DiffPorts(context.Background(), path, "", DiffOptions{Output: OutputJSON}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := json.Unmarshal([]byte(output), &diff); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, output)
+	}
+	return diff
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"sort"
+	"strings"
+
+	"portreleasor/internal/types"
+)
+
+// Supported --sort keys
+const (
+	SortByPort      = "port"
+	SortByPID       = "pid"
+	SortByProcess   = "process"
+	SortByUser      = "user"
+	SortByState     = "state"
+	SortByStartTime = "start-time"
+)
+
+// validateSort checks that the sort key is supported
+func validateSort(key string) error {
+	switch key {
+	case "", SortByPort, SortByPID, SortByProcess, SortByUser, SortByState, SortByStartTime:
+		return nil
+	}
+	return errorf(ErrInvalidPattern, "unsupported sort key %q (supported: %s, %s, %s, %s, %s, %s)",
+		key, SortByPort, SortByPID, SortByProcess, SortByUser, SortByState, SortByStartTime)
+}
+
+// sortPorts sorts port records by the given key, breaking ties with the default
+// port/protocol/address order
+func sortPorts(connections []types.PortInfo, key string, reverse bool) {
+	sort.SliceStable(connections, func(i, j int) bool {
+		a, b := connections[i], connections[j]
+		if reverse {
+			a, b = b, a
+		}
+		if c := comparePortsBy(a, b, key); c != 0 {
+			return c < 0
+		}
+		return a.Less(b)
+	})
+}
+
+// comparePortsBy compares two port records on a single key
+func comparePortsBy(a, b types.PortInfo, key string) int {
+	switch key {
+	case SortByPID:
+		return compareInts(a.PID, b.PID)
+	case SortByProcess:
+		return strings.Compare(strings.ToLower(a.ProcessName), strings.ToLower(b.ProcessName))
+	case SortByUser:
+		return strings.Compare(strings.ToLower(a.User), strings.ToLower(b.User))
+	case SortByState:
+		return strings.Compare(a.State, b.State)
+	case SortByStartTime:
+		// 启动时间未知的排在最后
+		switch {
+		case a.StartTime == nil && b.StartTime == nil:
+			return 0
+		case a.StartTime == nil:
+			return 1
+		case b.StartTime == nil:
+			return -1
+		}
+		return a.StartTime.Compare(*b.StartTime)
+	}
+	return 0
+}
+
+// compareInts returns -1, 0 or 1 comparing a and b
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
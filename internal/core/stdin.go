@@ -0,0 +1,307 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+	"portreleasor/internal/utils"
+)
+
+// What the numbers of a plain list on stdin are
+const (
+	StdinPorts = "port"
+	StdinPIDs  = "pid"
+)
+
+// startTimeTolerance absorbs the rounding of process start times between scans
+const startTimeTolerance = time.Second
+
+// releaseInput is what was read from stdin: port records as printed by check -o json,
+// and the ports and PIDs of plain lists
+type releaseInput struct {
+	records []types.PortInfo
+	targets []utils.PortTarget
+	pids    []int
+}
+
+// ReleaseInput releases the ports read from r, given as port records in JSON, such as
+// the output of check -o json with or without --group-by, as NDJSON with one record per
+// line, or as a plain list of ports or PIDs depending on list. Every record is
+// revalidated against a fresh scan first, and records whose socket is gone or now owned
+// by another process are skipped, so stale input cannot kill the wrong process. Records
+// without a PID are only released with opts.Force and a bind address.
+func ReleaseInput(ctx context.Context, r io.Reader, list string, opts ReleaseOptions) error {
+	if list != StdinPorts && list != StdinPIDs {
+		return errorf(ErrInvalidPattern, "unsupported list type %q (supported: %s, %s)", list, StdinPorts, StdinPIDs)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return i18n.Errorf("failed to read standard input: %v", err)
+	}
+	input, err := parseReleaseInput(data, list)
+	if err != nil {
+		return errorf(ErrInvalidPattern, "%w", err)
+	}
+	if len(input.records) == 0 && len(input.targets) == 0 && len(input.pids) == 0 {
+		return errorf(ErrInvalidPattern, "no ports or PIDs on standard input")
+	}
+
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	// 输入可能是之前保存的结果，必须重新扫描，不使用缓存
+	platform.Invalidate(manager)
+	// 每个监听套接字都要核对，如 IPv6 监听和共享端口的 nginx worker
+	connections, err := listeningSockets(ctx, manager)
+	if err != nil {
+		return err
+	}
+
+	matched := matchProcesses(connections, ProcessFilter{PIDs: input.pids})
+	if len(input.targets) > 0 {
+		sockets, err := targetSockets(ctx, manager, input.targets)
+		if err != nil {
+			return err
+		}
+		matched = append(matched, sockets...)
+	}
+	for _, record := range input.records {
+		conn, reason := revalidate(ctx, manager, connections, record, opts.Force)
+		if reason != "" {
+			i18n.Printf("Skipped stale entry %s: %s\n", describeRecord(record), reason)
+			continue
+		}
+		matched = append(matched, conn)
+	}
+
+	matched = uniqueSockets(matched)
+	if len(matched) == 0 {
+		i18n.Println("None of the piped entries matches the live state")
+		return errorf(ErrNoMatch, "no piped entry matches the live state")
+	}
+
+	i18n.Println("Processes using the piped ports:")
+	return releaseConnections(ctx, manager, matched, opts)
+}
+
+// parseReleaseInput reads JSON values, records or arrays of them, from data, or a list
+// of numbers separated by whitespace or commas if it does not start like JSON
+func parseReleaseInput(data []byte, list string) (releaseInput, error) {
+	var input releaseInput
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		for {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err == io.EOF {
+				break
+			} else if err != nil {
+				return input, i18n.Errorf("invalid JSON input: %v", err)
+			}
+			if err := input.addJSON(value, list); err != nil {
+				return input, err
+			}
+		}
+		return input, nil
+	}
+
+	fields := strings.FieldsFunc(string(trimmed), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	for _, field := range fields {
+		if err := input.addPlain(field, list); err != nil {
+			return input, err
+		}
+	}
+	return input, nil
+}
+
+// addJSON adds a port record, a number, or the records nested in an array or in the
+// groups, ports or targets of check --group-by and release reports
+func (in *releaseInput) addJSON(value json.RawMessage, list string) error {
+	switch trimmed := bytes.TrimSpace(value); {
+	case len(trimmed) == 0:
+		return nil
+	case trimmed[0] == '[':
+		var values []json.RawMessage
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return i18n.Errorf("invalid JSON input: %v", err)
+		}
+		for _, v := range values {
+			if err := in.addJSON(v, list); err != nil {
+				return err
+			}
+		}
+		return nil
+	case trimmed[0] != '{':
+		// jq -r '.[].pid' 之类的输出，按纯列表处理
+		var text interface{}
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return i18n.Errorf("invalid JSON input: %v", err)
+		}
+		return in.addPlain(fmt.Sprint(text), list)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return i18n.Errorf("invalid JSON input: %v", err)
+	}
+	if _, ok := fields["port"]; ok {
+		var record types.PortInfo
+		if err := json.Unmarshal(value, &record); err != nil {
+			return i18n.Errorf("invalid port record: %v", err)
+		}
+		if record.Port < 1 || record.Port > 65535 {
+			return i18n.Errorf("port %d out of range (1-65535)", record.Port)
+		}
+		in.records = append(in.records, record)
+		return nil
+	}
+
+	for _, key := range []string{"groups", "ports", "targets"} {
+		if nested, ok := fields[key]; ok {
+			return in.addJSON(nested, list)
+		}
+	}
+	return i18n.Errorf("invalid port record: missing %q", "port")
+}
+
+// addPlain adds one entry of a plain list, a port, port range or service name that may
+// be qualified as release arguments are, or a PID
+func (in *releaseInput) addPlain(entry, list string) error {
+	if list == StdinPIDs {
+		pid, err := strconv.Atoi(entry)
+		if err != nil || pid <= 0 {
+			return i18n.Errorf("invalid PID '%s'", entry)
+		}
+		in.pids = append(in.pids, pid)
+		return nil
+	}
+
+	target, err := utils.ParseTarget(entry)
+	if err != nil {
+		return err
+	}
+	in.targets = append(in.targets, target)
+	return nil
+}
+
+// revalidate finds the live socket of a piped record, returning why the record is
+// stale instead if its socket is gone or owned by another process than recorded.
+// Records without a PID are refused unless force is set and they name a bind address
+// held by a single process.
+func revalidate(ctx context.Context, manager platform.PlatformManager, connections []types.PortInfo, record types.PortInfo, force bool) (types.PortInfo, string) {
+	// 没有 PID 的条目（如所属进程被隐藏）无法确认所有者，可能误杀后来绑定的进程
+	if record.PID <= 0 && (!force || record.LocalAddr == "") {
+		return types.PortInfo{}, i18n.T("no PID recorded, use --force with a bind address to release it")
+	}
+
+	var onPort []types.PortInfo
+	for _, conn := range connections {
+		if conn.Port == record.Port && (record.Protocol == "" || strings.EqualFold(conn.Protocol, record.Protocol)) {
+			onPort = append(onPort, conn)
+		}
+	}
+
+	candidates := onPort
+	if record.LocalAddr != "" {
+		candidates = nil
+		for _, conn := range onPort {
+			if conn.LocalAddr == record.LocalAddr {
+				candidates = append(candidates, conn)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		if len(onPort) > 0 {
+			return types.PortInfo{}, i18n.Sprintf("now held by PID %d (%s)", onPort[0].PID, valueOrNA(onPort[0].ProcessName))
+		}
+		return types.PortInfo{}, i18n.T("no longer in use")
+	}
+
+	// 没有 PID 的条目只能按端口和地址匹配，多个进程共享该地址时无法确定要释放哪一个
+	conn := candidates[0]
+	if record.PID <= 0 {
+		var pids []int
+		for _, c := range candidates {
+			if !slices.Contains(pids, c.PID) {
+				pids = append(pids, c.PID)
+			}
+		}
+		if len(pids) > 1 {
+			slices.Sort(pids)
+			held := make([]string, len(pids))
+			for i, pid := range pids {
+				held[i] = strconv.Itoa(pid)
+			}
+			return types.PortInfo{}, i18n.Sprintf("held by several processes (PID %s), specify the PID", strings.Join(held, ", "))
+		}
+		return conn, ""
+	}
+	for _, c := range candidates {
+		if c.PID == record.PID {
+			conn = c
+		}
+	}
+
+	switch {
+	case conn.PID != record.PID:
+		return types.PortInfo{}, i18n.Sprintf("now held by PID %d (%s)", conn.PID, valueOrNA(conn.ProcessName))
+	case record.ProcessName != "" && conn.ProcessName != "" && !matchProcessName(conn.ProcessName, record.ProcessName):
+		return types.PortInfo{}, i18n.Sprintf("PID %d now runs %s", conn.PID, conn.ProcessName)
+	case record.StartTime != nil:
+		// PID 可能被复用，比较进程启动时间
+		startTime, err := manager.GetProcessStartTime(ctx, conn.PID)
+		if err == nil && absDuration(startTime.Sub(*record.StartTime)) > startTimeTolerance {
+			return types.PortInfo{}, i18n.Sprintf("PID %d was reused by a process started at %s",
+				conn.PID, startTime.Format(time.RFC3339))
+		}
+	}
+	return conn, ""
+}
+
+// describeRecord identifies a piped record in messages
+func describeRecord(record types.PortInfo) string {
+	if record.PID <= 0 {
+		return fmt.Sprintf("%d/%s", record.Port, record.Protocol)
+	}
+	if record.ProcessName == "" {
+		return fmt.Sprintf("%d/%s PID %d", record.Port, record.Protocol, record.PID)
+	}
+	return fmt.Sprintf("%d/%s PID %d (%s)", record.Port, record.Protocol, record.PID, record.ProcessName)
+}
+
+// uniqueSockets drops repeated sockets, e.g. a port both listed and piped as a record
+func uniqueSockets(connections []types.PortInfo) []types.PortInfo {
+	seen := make(map[string]bool)
+	var unique []types.PortInfo
+	for _, conn := range connections {
+		key := fmt.Sprintf("%d/%s/%s/%s/%d", conn.Port, conn.Protocol, conn.LocalAddr, conn.RemoteAddr, conn.PID)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, conn)
+		}
+	}
+	return unique
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
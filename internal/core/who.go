@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+)
+
+// WhoProcess lists every socket held by the processes matching target (a PID or
+// a process name) and by their child processes
+func WhoProcess(ctx context.Context, target string) error {
+	manager := platform.GetPlatformManager()
+	if manager == nil {
+		return errorf(ErrBackendUnavailable, "unsupported platform")
+	}
+
+	processes, err := manager.ListProcesses(ctx)
+	if err != nil {
+		return errorf(backendKind(err), "failed to list processes: %v", err)
+	}
+
+	names := make(map[int]string)
+	children := make(map[int][]int)
+	for _, proc := range processes {
+		names[proc.PID] = proc.Name
+		if proc.PPID != proc.PID {
+			children[proc.PPID] = append(children[proc.PPID], proc.PID)
+		}
+	}
+
+	var roots []int
+	if pid, err := strconv.Atoi(target); err == nil {
+		if _, exists := names[pid]; exists {
+			roots = append(roots, pid)
+		}
+	} else {
+		for _, proc := range processes {
+			if matchProcessName(proc.Name, target) {
+				roots = append(roots, proc.PID)
+			}
+		}
+	}
+
+	if len(roots) == 0 {
+		i18n.Printf("No process found matching %q\n", target)
+		return errorf(ErrNoMatch, "no process found matching %q", target)
+	}
+	sort.Ints(roots)
+
+	sockets, err := manager.GetAllSockets(ctx)
+	if err != nil {
+		return collectError(err)
+	}
+
+	byPID := make(map[int][]types.PortInfo)
+	for _, sock := range sockets {
+		byPID[sock.PID] = append(byPID[sock.PID], sock)
+	}
+	for _, socks := range byPID {
+		types.SortPortInfos(socks)
+	}
+
+	w := whoWriter{
+		names:       names,
+		children:    children,
+		sockets:     byPID,
+		visited:     make(map[int]bool),
+		localWidth:  len("LOCAL ADDRESS"),
+		remoteWidth: len("REMOTE ADDRESS"),
+	}
+	for _, sock := range sockets {
+		w.localWidth = max(w.localWidth, len(sock.LocalAddr))
+		w.remoteWidth = max(w.remoteWidth, len(sock.RemoteAddr))
+	}
+
+	for _, pid := range roots {
+		// 匹配的子进程（如 nginx worker）已在父进程下展示
+		if !w.visited[pid] {
+			w.print(pid, 0, 0)
+		}
+	}
+
+	i18n.Printf("\n%d socket(s) held by %d process(es)\n", w.socketCount, len(w.visited))
+
+	return nil
+}
+
+// whoWriter prints the process tree with the sockets of each process
+type whoWriter struct {
+	names       map[int]string
+	children    map[int][]int
+	sockets     map[int][]types.PortInfo
+	visited     map[int]bool
+	localWidth  int
+	remoteWidth int
+	socketCount int
+}
+
+// print prints one process and its sockets, then recurses into its children
+func (w *whoWriter) print(pid int, ppid int, depth int) {
+	w.visited[pid] = true
+	indent := strings.Repeat("   ", depth)
+
+	if depth == 0 {
+		fmt.Printf("\nPID %d  %s\n", pid, w.names[pid])
+	} else {
+		i18n.Printf("%s└─ PID %d  %s (child of %d)\n", strings.Repeat("   ", depth-1), pid, w.names[pid], ppid)
+	}
+
+	socks := w.sockets[pid]
+	if len(socks) == 0 {
+		i18n.Printf("%s  (no sockets)\n", indent)
+	} else {
+		fmt.Printf("%s  %-6s %-*s %-*s %s\n", indent,
+			"PROTO", w.localWidth, "LOCAL ADDRESS", w.remoteWidth, "REMOTE ADDRESS", "STATE")
+		for _, sock := range socks {
+			fmt.Printf("%s  %-6s %-*s %-*s %s\n", indent,
+				sock.Protocol, w.localWidth, sock.LocalAddr, w.remoteWidth, sock.RemoteAddr, sock.State)
+		}
+		w.socketCount += len(socks)
+	}
+
+	kids := append([]int(nil), w.children[pid]...)
+	sort.Ints(kids)
+	for _, child := range kids {
+		if !w.visited[child] {
+			w.print(child, pid, depth+1)
+		}
+	}
+}
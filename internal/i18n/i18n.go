@@ -0,0 +1,165 @@
+// Package i18n translates the user-facing messages of the CLI. Messages are written in
+// English in the source and serve as the keys of the catalogs, so the English catalog
+// is the identity and a message missing from a catalog is shown in English.
+// Structured outputs such as JSON keep the English messages.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Chinese = "zh-CN"
+)
+
+// catalogs maps each language but English to its translations
+var catalogs = map[string]map[string]string{
+	Chinese: zhCN,
+}
+
+var language atomic.Value
+
+func init() {
+	language.Store(English)
+}
+
+// Languages returns the supported languages
+func Languages() []string {
+	languages := []string{English}
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages[1:])
+	return languages
+}
+
+// Parse returns the supported language of a --lang value or locale such as
+// zh_CN.UTF-8, en_US or C
+func Parse(locale string) (string, bool) {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "_", "-")
+
+	switch {
+	case locale == "zh" || strings.HasPrefix(locale, "zh-"):
+		return Chinese, true
+	case locale == "en" || strings.HasPrefix(locale, "en-") || locale == "c" || locale == "posix":
+		return English, true
+	}
+	return "", false
+}
+
+// Detect returns the language selected by the --lang value if set, otherwise by the
+// LC_ALL, LC_MESSAGES and LANG environment variables, English if none is supported
+func Detect(flag string) (string, error) {
+	if flag != "" {
+		lang, ok := Parse(flag)
+		if !ok {
+			return "", Errorf("unsupported language %q (supported: %s)", flag, strings.Join(Languages(), ", "))
+		}
+		return lang, nil
+	}
+
+	// 与 setlocale 的优先级一致，第一个非空的变量决定语言
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if lang, ok := Parse(value); ok {
+				return lang, nil
+			}
+			break
+		}
+	}
+	return English, nil
+}
+
+// SetLanguage selects the language of the messages, one of Languages()
+func SetLanguage(lang string) {
+	language.Store(lang)
+}
+
+// Language returns the selected language
+func Language() string {
+	return language.Load().(string)
+}
+
+// T translates a message into the selected language
+func T(message string) string {
+	if translated, ok := catalogs[Language()][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf formats according to the translation of format
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Printf prints to stdout according to the translation of format
+func Printf(format string, args ...interface{}) {
+	fmt.Printf(T(format), args...)
+}
+
+// Fprintf prints to w according to the translation of format
+func Fprintf(w io.Writer, format string, args ...interface{}) {
+	fmt.Fprintf(w, T(format), args...)
+}
+
+// Println prints the translation of message to stdout, followed by a newline
+func Println(message string) {
+	fmt.Println(T(message))
+}
+
+// Error is an error whose message is translated when shown to the user. Error()
+// returns the English message; Localize returns the translated one.
+type Error struct {
+	format string
+	args   []interface{}
+	err    error
+}
+
+// Errorf returns an error formatted like fmt.Errorf, including a %w verb, that
+// Localize translates
+func Errorf(format string, args ...interface{}) error {
+	return &Error{format: format, args: args, err: errors.Unwrap(fmt.Errorf(format, args...))}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(strings.ReplaceAll(e.format, "%w", "%v"), e.args...)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Localize returns the message in the selected language, including the messages of
+// the errors it was formatted with
+func (e *Error) Localize() string {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		if err, ok := arg.(error); ok {
+			arg = Localize(err)
+		}
+		args[i] = arg
+	}
+	return fmt.Sprintf(strings.ReplaceAll(T(e.format), "%w", "%v"), args...)
+}
+
+// Localize returns the message of err in the selected language, or err.Error() if it
+// cannot be translated
+func Localize(err error) string {
+	if l, ok := err.(interface{ Localize() string }); ok {
+		return l.Localize()
+	}
+	return err.Error()
+}
@@ -0,0 +1,308 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"zh_CN.UTF-8", Chinese, true},
+		{"zh-CN", Chinese, true},
+		{"zh_TW", Chinese, true},
+		{"zh", Chinese, true},
+		{"en_US.UTF-8", English, true},
+		{"en", English, true},
+		{"C", English, true},
+		{"POSIX", English, true},
+		{"C.UTF-8", English, true},
+		{"de_DE.UTF-8", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.locale)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.locale, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", want: English},
+		{name: "flag", flag: "zh-CN", env: map[string]string{"LANG": "en_US.UTF-8"}, want: Chinese},
+		{name: "flag overrides LC_ALL", flag: "en", env: map[string]string{"LC_ALL": "zh_CN.UTF-8"}, want: English},
+		{name: "unsupported flag", flag: "fr", wantErr: true},
+		{name: "LANG", env: map[string]string{"LANG": "zh_CN.UTF-8"}, want: Chinese},
+		{name: "LC_ALL overrides LANG", env: map[string]string{"LC_ALL": "C", "LANG": "zh_CN.UTF-8"}, want: English},
+		{name: "LC_MESSAGES", env: map[string]string{"LC_MESSAGES": "zh_CN", "LANG": "en_US"}, want: Chinese},
+		{name: "unsupported locale", env: map[string]string{"LC_ALL": "de_DE.UTF-8", "LANG": "zh_CN.UTF-8"}, want: English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(name, tt.env[name])
+			}
+
+			got, err := Detect(tt.flag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect(%q) error = %v, wantErr %v", tt.flag, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	defer SetLanguage(English)
+	SetLanguage(Chinese)
+
+	cause := Errorf("failed to execute %s: %w", "ps", errors.New("exit status 1"))
+	err := Errorf("failed to list processes: %v", cause)
+
+	if got, want := err.Error(), "failed to list processes: failed to execute ps: exit status 1"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := Localize(err), "列出进程失败: 执行 ps 失败: exit status 1"; got != want {
+		t.Errorf("Localize() = %q, want %q", got, want)
+	}
+
+	wrapped := Errorf("failed to kill process %d: %w", 42, fs.ErrPermission)
+	if !errors.Is(wrapped, fs.ErrPermission) {
+		t.Error("Errorf does not wrap the %w operand")
+	}
+}
+
+// translated 调用时第几个参数是待翻译的消息
+var translated = map[string]int{
+	"T":             0,
+	"Sprintf":       0,
+	"Printf":        0,
+	"Println":       0,
+	"Errorf":        0,
+	"Fprintf":       1,
+	"errorf":        1,
+	"reportf":       1,
+	"exitWithError": 1,
+	"log":           0,
+}
+
+// translatedFields 保存英文消息、在输出时才翻译的字段
+var translatedFields = map[string]bool{
+	"Role": true, "role": true, "Note": true, "note": true, "failNote": true, "Purpose": true,
+	"Short": true, "Long": true, "Protected": true,
+}
+
+// flagDefinition 匹配 pflag 定义参数的方法，最后一个参数是参数说明
+var flagDefinition = regexp.MustCompile(`^(Bool|String|Int|Duration|StringSlice|IntSlice|StringToString)(Var)?P?$`)
+
+// verbRegexp 匹配格式化动词，译文可用 %[n]d 调整参数顺序
+var verbRegexp = regexp.MustCompile(`%[-+# 0]*(\[\d+\])?(\d+|\*)?(\.(\d+|\*))?[a-zA-Z%]`)
+
+// argIndex 匹配格式化动词中的参数序号
+var argIndex = regexp.MustCompile(`\[\d+\]`)
+
+// TestCatalogComplete 检查源代码中所有待翻译的英文消息在每个目录中都有译文，且格式化动词一致
+func TestCatalogComplete(t *testing.T) {
+	messages := sourceMessages(t, filepath.Join("..", ".."))
+	if len(messages) < 100 {
+		t.Fatalf("found only %d messages, the extraction is broken", len(messages))
+	}
+
+	for lang, catalog := range catalogs {
+		var missing []string
+		for message, position := range messages {
+			translation, ok := catalog[message]
+			if !ok {
+				missing = append(missing, fmt.Sprintf("%s: %q", position, message))
+				continue
+			}
+			if got, want := verbs(translation), verbs(message); got != want {
+				t.Errorf("%s: translation of %q has verbs %s, want %s", lang, message, got, want)
+			}
+		}
+		sort.Strings(missing)
+		for _, m := range missing {
+			t.Errorf("%s: missing translation at %s", lang, m)
+		}
+	}
+}
+
+// verbs returns the sorted formatting verbs of a message, without argument indexes
+func verbs(message string) string {
+	found := verbRegexp.FindAllString(message, -1)
+	for i, verb := range found {
+		found[i] = argIndex.ReplaceAllString(verb, "")
+	}
+	sort.Strings(found)
+	return strings.Join(found, " ")
+}
+
+// sourceMessages collects the messages passed to the translation functions, stored in
+// fields translated when printed, used as cobra help texts or returned by protectionReason
+func sourceMessages(t *testing.T, root string) map[string]string {
+	messages := make(map[string]string)
+	fset := token.NewFileSet()
+
+	add := func(expr ast.Expr) {
+		if message, ok := literal(expr); ok && hasWords(message) {
+			if _, exists := messages[message]; !exists {
+				messages[message] = fset.Position(expr.Pos()).String()
+			}
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "i18n" || strings.HasPrefix(d.Name(), ".")) && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CallExpr:
+				name := calleeName(n.Fun)
+				if index, ok := translated[name]; ok && index < len(n.Args) && isTranslating(n.Fun) {
+					add(n.Args[index])
+				}
+				if flagDefinition.MatchString(name) && len(n.Args) > 0 {
+					add(n.Args[len(n.Args)-1])
+				}
+			case *ast.KeyValueExpr:
+				if key, ok := n.Key.(*ast.Ident); ok && translatedFields[key.Name] {
+					add(n.Value)
+				}
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if sel, ok := lhs.(*ast.SelectorExpr); ok && translatedFields[sel.Sel.Name] && i < len(n.Rhs) {
+						add(n.Rhs[i])
+					}
+				}
+			case *ast.FuncDecl:
+				if n.Name.Name == "protectionReason" || n.Name.Name == "elevationHint" {
+					ast.Inspect(n.Body, func(node ast.Node) bool {
+						if ret, ok := node.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+							add(ret.Results[0])
+						}
+						return true
+					})
+				}
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if strings.HasSuffix(name.Name, "Reason") && i < len(n.Values) {
+						add(n.Values[i])
+					}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return messages
+}
+
+// calleeName returns the name of the called function or method
+func calleeName(fun ast.Expr) string {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
+
+// isTranslating reports whether the call goes to this package, or to an unqualified
+// helper or method such as errorf or g.log that translates its format
+func isTranslating(fun ast.Expr) bool {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return true
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	switch pkg.Name {
+	case "i18n":
+		return true
+	case "fmt", "os", "strings", "errors", "cmd", "io", "w":
+		return false
+	}
+	return sel.Sel.Name == "log"
+}
+
+// literal returns the value of a string literal or a concatenation of them
+func literal(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(e.Value)
+		return value, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := literal(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := literal(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return literal(e.X)
+	case *ast.CallExpr:
+		// 如 i18n.T("...") 作为字段值
+		if calleeName(e.Fun) == "T" && len(e.Args) == 1 {
+			return literal(e.Args[0])
+		}
+	}
+	return "", false
+}
+
+// hasWords reports whether a message contains text besides formatting verbs
+func hasWords(message string) bool {
+	return strings.ContainsFunc(verbRegexp.ReplaceAllString(message, ""), func(r rune) bool {
+		return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+	})
+}
@@ -0,0 +1,681 @@
+package i18n
+
+// zhCN 简体中文消息目录，键为源代码中的英文消息
+var zhCN = map[string]string{
+	// 命令行
+	"Cross-platform port releasing tool": "跨平台端口释放工具",
+	`PortReleasor is a cross-platform port management tool
+that checks port usage and releases the specified ports
+
+The system tools it calls (ss, netstat, lsof, ps, tasklist, wmic, etc.) run for at most
+10 seconds by default; adjust this with --timeout, or per tool with --backend-timeout lsof=30s.
+Timeouts and switches to a fallback tool are reported on standard error; press Ctrl-C to cancel at any time
+
+Messages are shown in the language selected by --lang, otherwise by the LC_ALL,
+LC_MESSAGES or LANG environment variables (en, zh-CN); JSON output is always in English
+
+Exit codes: 0 success, 1 other error, 2 invalid argument, port or pattern, 3 no match, 4 permission denied,
+5 partial failure, 6 protected process, 7 no backend available, 8 backend timeout,
+9 differences found (diff --exit-code), 10 policy violations (guard --once), 130 cancelled`: `PortReleasor 是一个跨平台的端口管理工具，
+可以检查端口占用情况并释放指定端口
+
+调用的系统工具（ss、netstat、lsof、ps、tasklist、wmic 等）默认最多运行 10 秒，
+可用 --timeout 统一调整，或用 --backend-timeout lsof=30s 单独设置；
+超时或切换到备用工具时会在标准错误输出提示，按 Ctrl-C 可随时取消
+
+消息语言由 --lang 指定，未指定时取自环境变量 LC_ALL、LC_MESSAGES 或 LANG（en、zh-CN）；
+JSON 输出始终使用英文
+
+退出码：0 成功，1 其他错误，2 无效的参数、端口或模式，3 没有匹配项，4 权限不足，
+5 部分失败，6 进程受保护，7 没有可用的后端，8 后端超时，
+9 存在差异（diff --exit-code），10 违反策略（guard --once），130 已取消`,
+	"Release the specified ports": "释放指定端口",
+	`Release ports in use, given as:
+- a single port: 8080
+- several ports: 8080 8081 8082
+- a port range: 8080-8090
+- qualified by protocol, local address or interface, sparing the other sockets on the port:
+  53/udp, 127.0.0.1:8080, [::1]:9000, docker0:5432/tcp
+
+All ports held by processes can be released as well:
+--process java           by process name
+--pid 1234               by process ID
+--exe /opt/app/bin/server by executable path
+
+--stdin reads the ports to release from standard input, as the JSON output of check
+(one record per line is accepted too) or a list of ports; --stdin-pids reads a list of PIDs.
+Every record is checked against the live state first, and entries whose port is free
+or now held by another process are skipped:
+  portreleasor check --user deploy --process node -o json | jq '...' | portreleasor release --stdin
+The confirmation is read from the terminal, use -f when there is none
+
+By default the processes to kill are selected one by one (e.g. 1,3-4; a all, n none, ? details)
+-f kills every process without confirmation
+--non-interactive asks a single y/N confirmation, suitable for input piped in from scripts
+--sudo runs with administrator privileges through sudo/doas/pkexec; without it, processes
+that could not be killed for lack of privileges are offered for an elevated retry in a
+terminal, naming the missing privileges (e.g. CAP_KILL)`: `释放被占用的端口，支持：
+- 单个端口: 8080
+- 多个端口: 8080 8081 8082
+- 端口范围: 8080-8090
+- 限定协议、本地地址或网卡，不影响该端口上的其他套接字:
+  53/udp、127.0.0.1:8080、[::1]:9000、docker0:5432/tcp
+
+也可以按进程释放其占用的全部端口：
+--process java           按进程名
+--pid 1234               按进程ID
+--exe /opt/app/bin/server 按可执行文件路径
+
+--stdin 从标准输入读取要释放的端口，格式为 check 的 JSON 输出（也支持每行一条记录）
+或端口列表；--stdin-pids 读取进程ID列表。每条记录都会先与当前实时状态核对，
+端口已空闲或已被其他进程占用的条目会被跳过：
+  portreleasor check --user deploy --process node -o json | jq '...' | portreleasor release --stdin
+确认从终端读取，没有终端时请使用 -f
+
+默认逐个进程选择要终止的进程（如 1,3-4；a 全部，n 取消，? 查看详情）
+-f 无需确认直接终止全部进程
+--non-interactive 使用单次 y/N 确认，适合脚本通过管道输入
+--sudo 通过 sudo/doas/pkexec 以管理员权限运行；未指定时，因权限不足
+无法终止的进程会在终端中询问是否提权重试，并说明缺少的权限（如 CAP_KILL）`,
+	"read the ports to release from standard input: JSON records or a list of ports": "从标准输入读取要释放的端口: JSON 记录或端口列表",
+	"read a list of PIDs to release from standard input":                             "从标准输入读取要释放的进程ID列表",
+	"Check port usage": "检查端口占用情况",
+	`Check port usage, showing the port, process ID, protocol and program
+-v shows the absolute path, full command line, working directory and start time of the program
+-w matches wildcard patterns
+-o output format: table (default) or json
+--group-by groups by process|user|container|protocol
+--sort sorts by port|pid|process|user|state|start-time, --reverse reverses the order
+Sorted by port, protocol and address by default
+--save saves the result as a snapshot file to compare with the diff command
+--process and --user keep only the ports of processes with these names or owners
+--sudo runs with administrator privileges through sudo/doas/pkexec to show processes of other users;
+without it, rows left incomplete for lack of privileges are summarized at the end,
+naming the missing privileges, with an offer to re-run elevated`: `检查端口占用情况，显示端口、进程ID、协议和程序信息
+-v 显示程序的绝对路径、完整命令行、工作目录和启动时间
+-w 通配符模式匹配
+-o 输出格式：table（默认）或 json
+--group-by 按 process|user|container|protocol 分组显示
+--sort 按 port|pid|process|user|state|start-time 排序，--reverse 倒序
+默认按端口、协议、地址排序
+--save 将结果保存为快照文件，配合 diff 命令比较
+--process 和 --user 只显示指定名称或用户的进程占用的端口
+--sudo 通过 sudo/doas/pkexec 以管理员权限运行，显示其他用户进程的信息；
+未指定时，若因权限不足有行不完整，会在末尾说明缺少的权限并询问是否提权重新运行`,
+	"invalid timeout %s=%s":                                                                 "无效的超时时间 %s=%s",
+	"invalid timeout %s":                                                                    "无效的超时时间 %s",
+	"timeout of each backend command":                                                       "每个后端命令的超时时间",
+	"timeout of individual backend commands, e.g. lsof=30s,ss=2s":                           "单独设置后端命令的超时时间，如 lsof=30s,ss=2s",
+	"release without confirmation":                                                          "强制释放，无需确认",
+	"non-interactive mode, with a single y/N confirmation":                                  "非交互模式，使用单次 y/N 确认",
+	"release all ports held by processes with this name":                                    "按进程名释放其占用的全部端口",
+	"release all ports held by the process with this ID":                                    "按进程ID释放其占用的全部端口",
+	"release all ports held by processes running this executable":                           "按可执行文件路径释放其占用的全部端口",
+	"show the absolute path, command line, working directory and start time of the program": "显示程序的绝对路径、命令行、工作目录和启动时间",
+	"match wildcard patterns":                                                               "通配符模式匹配",
+	"output format: table|json":                                                             "输出格式: table|json",
+	"group by: process|user|container|protocol":                                             "分组方式: process|user|container|protocol",
+	"sort by: port|pid|process|user|state|start-time":                                       "排序方式: port|pid|process|user|state|start-time",
+	"reverse the sort order":                                                                "倒序排列",
+	"only show ports held by processes with this name":                                      "只显示指定名称的进程占用的端口",
+	"only show ports held by processes of this user":                                        "只显示指定用户的进程占用的端口",
+	"save a snapshot to the file":                                                           "保存快照到指定文件",
+	"ports cannot be combined with --process/--pid/--exe":                                   "端口参数不能与 --process/--pid/--exe 同时使用",
+	"specify ports, processes with --process/--pid/--exe, or --stdin":                       "请指定端口、使用 --process/--pid/--exe 指定进程，或使用 --stdin",
+	"--stdin cannot be combined with --stdin-pids":                                          "--stdin 不能与 --stdin-pids 同时使用",
+	"--stdin/--stdin-pids cannot be combined with ports or --process/--pid/--exe":           "--stdin/--stdin-pids 不能与端口或 --process/--pid/--exe 同时使用",
+	"no terminal to confirm the release of piped ports, use --force: %w":                    "没有可用于确认释放管道输入端口的终端，请使用 --force: %w",
+	"failed to release ports":                                                               "释放端口失败",
+	"failed to check ports":                                                                 "检查端口失败",
+	"Compare port states at two points in time":                                             "比较两个时间点的端口状态",
+	`Compare snapshots saved by check --save, reporting ports that were opened, closed or
+taken over by another process
+With a single snapshot, compare it with the live state, filtered as the snapshot was
+--exit-code exits with status 9 if there are differences, for checks in test or deployment scripts`: `比较 check --save 保存的快照，报告新开放、已关闭和占用进程发生变化的端口
+只指定一个快照时与当前实时状态比较，并按保存快照时的条件筛选
+--exit-code 存在差异时以退出码 9 退出，便于在测试或部署脚本中校验`,
+	"exit with status 9 if there are differences":           "存在差异时以退出码 9 退出",
+	"failed to compare port states":                         "比较端口状态失败",
+	"Check the port discovery backends and the environment": "检查端口发现后端和运行环境",
+	`Check which port discovery backends work on this host, with their versions and timings,
+and show the backend check actually uses
+On Linux it checks ss, netstat, lsof, /proc, netlink and ps in turn, and reports:
+- whether /proc is mounted with hidepid
+- whether it runs in WSL, a container or a user namespace
+- the capabilities currently held
+Exits with status 7 if no socket collector works`: `检查本机可用的端口发现后端及其版本和耗时，并显示 check 实际使用的后端
+在Linux上依次检查 ss、netstat、lsof、/proc、netlink 和 ps，并报告：
+- /proc 是否以 hidepid 挂载
+- 是否运行在 WSL、容器或用户命名空间中
+- 当前拥有的能力（capabilities）
+没有可用的套接字采集器时退出码为 7`,
+	"environment check failed":           "环境检查失败",
+	"Diagnose why a port is unavailable": "诊断端口为何不可用",
+	`Diagnose why a port cannot be bound (address already in use) and suggest remediations
+On Linux it also checks:
+- listeners in other network namespaces (containers)
+- connections in TIME_WAIT
+- ip_local_reserved_ports and the ephemeral port range
+- privileged ports and CAP_NET_BIND_SERVICE
+- NFS/RPC sockets held by the kernel`: `诊断端口无法绑定（address already in use）的原因，并给出处理建议
+在Linux上还会检查：
+- 其他网络命名空间（容器）中的监听者
+- TIME_WAIT 状态的连接
+- ip_local_reserved_ports 保留端口与临时端口范围
+- 特权端口与 CAP_NET_BIND_SERVICE
+- 内核持有的 NFS/RPC 套接字`,
+	"failed to diagnose port":               "诊断端口失败",
+	"Start the Prometheus metrics exporter": "启动 Prometheus 指标导出器",
+	`Serve Prometheus metrics on /metrics, rescanning the sockets on every scrape
+  portreleasor_listening_sockets      listening sockets by port, protocol and process
+  portreleasor_connections            non-listening sockets by protocol and state
+  portreleasor_scrape_duration_seconds  duration of the scan
+  portreleasor_scrape_success         whether the last scan succeeded
+  portreleasor_scrape_errors_total    number of failed scans
+
+The serve command provides /metrics as well`: `在 /metrics 提供 Prometheus 指标，每次抓取时重新扫描套接字
+  portreleasor_listening_sockets      按端口、协议和进程统计的监听套接字数
+  portreleasor_connections            按协议和状态统计的非监听套接字数
+  portreleasor_scrape_duration_seconds  采集耗时
+  portreleasor_scrape_success         最近一次采集是否成功
+  portreleasor_scrape_errors_total    采集失败次数
+
+serve 命令同样提供 /metrics`,
+	"address to listen on":                               "监听地址",
+	"failed to start the metrics exporter":               "启动指标导出器失败",
+	"Watch listening ports against an allow-list policy": "按白名单策略监控监听端口",
+	`Continuously watch the listening ports, logging listeners missing from the allow list,
+notifying a webhook, and optionally releasing them after a grace period (release: true).
+Protected system processes are never released
+
+Example policy:
+  interval: 5s
+  grace_period: 30s
+  release: false
+  webhook: https://hooks.example.com/ports
+  allow:
+    - port: 22
+      process: sshd
+    - port: 8000-8100
+      address: 127.0.0.1
+      process: "python*"
+      user: deploy
+
+--once checks only once and exits with status 10 on violations`: `持续监控监听端口，对不在白名单中的监听者记录日志、调用 webhook 通知，
+并可在宽限期后释放（release: true）。受保护的系统进程不会被释放
+
+策略示例:
+  interval: 5s
+  grace_period: 30s
+  release: false
+  webhook: https://hooks.example.com/ports
+  allow:
+    - port: 22
+      process: sshd
+    - port: 8000-8100
+      address: 127.0.0.1
+      process: "python*"
+      user: deploy
+
+--once 只检查一次，存在违规时以退出码 10 退出`,
+	"policy file (YAML)": "策略文件 (YAML)",
+	"check only once and exit with status 10 on violations": "只检查一次，存在违规时以退出码 10 退出",
+	"failed to guard ports":                                 "端口策略监控失败",
+	"Start the HTTP API server":                             "启动 HTTP API 服务",
+	`Start a JSON REST API for dashboards, IDE plugins and orchestration scripts
+  GET  /ports          list ports, with the pattern, wildcard, sort, reverse and group_by query parameters
+  GET  /ports/{port}   look up a single port (port number or service name)
+  POST /release        release ports according to a plan, e.g. {"ports":["8080"],"dry_run":true},
+                       sent as application/json
+  GET  /events         Server-Sent Events stream of port changes
+
+Once --token or the PORTRELEASOR_TOKEN environment variable is set, every request must carry
+Authorization: Bearer <token>. Without a token only loopback addresses may be listened on,
+POST /release is disabled, and requests must name a loopback host, e.g. localhost`: `启动 JSON REST API，供仪表盘、IDE 插件和编排脚本调用
+  GET  /ports          列出端口，支持 pattern、wildcard、sort、reverse、group_by 查询参数
+  GET  /ports/{port}   查询单个端口（端口号或服务名）
+  POST /release        按计划释放端口，请求体如 {"ports":["8080"],"dry_run":true}，
+                       以 application/json 发送
+  GET  /events         端口变化的 Server-Sent Events 事件流
+
+--token 或环境变量 PORTRELEASOR_TOKEN 设置后，所有请求必须携带 Authorization: Bearer <token>
+未设置令牌时只允许监听回环地址，POST /release 被禁用，且请求的主机名必须是回环地址（如 localhost）`,
+	"bearer token (default from PORTRELEASOR_TOKEN)": "Bearer 令牌（默认读取 PORTRELEASOR_TOKEN）",
+	"scan interval of the event stream":              "事件流的扫描间隔",
+	"failed to start the API server":                 "启动 API 服务失败",
+	"cannot locate the running executable: %w":       "无法定位当前程序: %w",
+	`Re-running with %s...
+`: `使用 %s 提权重新运行...
+`,
+	`failed to elevate privileges: %s
+`: `提权失败: %s
+`,
+	"run with administrator privileges through sudo/doas/pkexec": "通过 sudo/doas/pkexec 以管理员权限运行",
+	"Show all ports held by a process":                           "查看进程占用的全部端口",
+	`Look up all sockets (listening and established) held by a process ID or name,
+showing the protocol, local address, remote address and state, including the sockets
+of its child processes`: `按进程ID或进程名反查其持有的全部套接字（监听和已建立的连接），
+显示协议、本地地址、远端地址和状态，并包含其子进程的套接字`,
+	"failed to look up process ports":                                               "查询进程端口失败",
+	"language of the messages: en|zh-CN (default from LC_ALL, LC_MESSAGES or LANG)": "消息语言: en|zh-CN（默认取自 LC_ALL、LC_MESSAGES 或 LANG）",
+	"help for %s": "%s 的帮助信息",
+	`Warning: %s
+`: `警告: %s
+`,
+	`Operation cancelled
+`: `操作已取消
+`,
+	`Error: %s
+`: `错误: %s
+`,
+	"Usage:":                  "用法:",
+	"Aliases:":                "别名:",
+	"Examples:":               "示例:",
+	"Available Commands:":     "可用命令:",
+	"Additional Commands:":    "其他命令:",
+	"Global Flags:":           "全局参数:",
+	"Flags:":                  "参数:",
+	"Additional help topics:": "其他帮助主题:",
+	"Use \"{{.CommandPath}} [command] --help\" for more information about a command.": "使用 \"{{.CommandPath}} [command] --help\" 查看命令的详细信息",
+	"Help about any command": "查看命令的帮助",
+	"Generate the autocompletion script for the specified shell": "生成指定 shell 的自动补全脚本",
+
+	// 核心逻辑
+	"unsupported platform":                                        "不支持的平台",
+	"invalid wildcard pattern %q: only digits are allowed":        "无效的通配符模式 %q: 只能包含数字",
+	"invalid pattern %q: not a port number or known service name": "无效的模式 %q: 不是端口号或已知的服务名",
+	`Snapshot of %d port(s) saved to %s
+`: `已将 %d 个端口的快照保存到 %s
+`,
+	"no matching ports found": "没有找到匹配的端口",
+	"No matching ports found": "没有找到匹配的端口",
+	`
+Showing %d unique port(s) in %d group(s)
+`: `
+共 %d 个端口，%d 个分组
+`,
+	`
+Showing %d unique port(s)
+`: `
+共 %d 个端口
+`,
+	`Warning: %d/%s (%s) is served by %s (PID %d), expected %s
+`: `警告: %d/%s (%s) 由 %s (PID %d) 提供，预期为 %s
+`,
+	"Re-run with elevated privileges to show them?":                  "是否提权重新运行以显示这些信息？",
+	"Note: %d of %d row(s) are incomplete due to missing privileges": "注意: %[2]d 行中有 %[1]d 行因权限不足而不完整",
+	" (missing %s)":    "（缺少 %s）",
+	"; %s to see them": "；%s 即可查看",
+	"unsupported output format %q (supported: %s, %s)": "不支持的输出格式 %q（支持: %s、%s）",
+	"failed to encode JSON: %v":                        "JSON 编码失败: %v",
+	"<permission denied>":                              "<权限不足>",
+	"<process gone>":                                   "<进程已退出>",
+	"<unsupported>":                                    "<不支持>",
+	"N/A":                                              "无",
+	`%s  [%d port(s)]
+`: `%s  [%d 个端口]
+`,
+	"host": "主机",
+	"unsupported group %q (supported: %s, %s, %s, %s)":            "不支持的分组方式 %q（支持: %s、%s、%s、%s）",
+	"unsupported sort key %q (supported: %s, %s, %s, %s, %s, %s)": "不支持的排序方式 %q（支持: %s、%s、%s、%s、%s、%s）",
+	"%s to %s":                         "%s（用于%s）",
+	"re-run with --sudo":               "使用 --sudo 重新运行",
+	"run from an Administrator prompt": "在管理员命令提示符中运行",
+	"run as root":                      "以 root 身份运行",
+	`Could not elevate privileges: %s
+`: `无法提权: %s
+`,
+	"owner process unknown (insufficient privileges?)": "所属进程未知（权限不足？）",
+	"init process":            "init 进程",
+	"portreleasor itself":     "portreleasor 自身",
+	"critical system process": "关键系统进程",
+	"owner hidden, insufficient privileges to identify it": "所属进程被隐藏，权限不足无法识别",
+	"failed to get port connections: %w":                   "获取端口连接失败: %w",
+	"No processes found using the specified ports":         "没有找到占用指定端口的进程",
+	"no processes found using the specified ports":         "没有找到占用指定端口的进程",
+	"Processes using the specified ports:":                 "占用指定端口的进程:",
+	"no process name, PID or executable specified":         "未指定进程名、PID 或可执行文件",
+	"No ports held by the specified processes":             "指定的进程没有占用端口",
+	"no ports held by the specified processes":             "指定的进程没有占用端口",
+	"Ports held by the specified processes:":               "指定进程占用的端口:",
+	"no ports or processes specified":                      "未指定端口或进程",
+	`
+The owner of %d port(s) is hidden for lack of privileges`: `
+因权限不足，%d 个端口的所属进程被隐藏`,
+	"Re-run with elevated privileges to release them?": "是否提权重新运行以释放这些端口？",
+	`
+All matching processes are protected, nothing to release`: `
+所有匹配的进程均受保护，没有可释放的端口`,
+	"refusing to kill process(es) that could not be identified": "拒绝终止无法识别的进程",
+	"refusing to kill protected process(es)":                    "拒绝终止受保护的进程",
+	"Operation cancelled":                                       "操作已取消",
+	`
+Killing processes...`: `
+正在终止进程...`,
+	`Skipped process %d: %s
+`: `已跳过进程 %d: %s
+`,
+	`Successfully killed process %d
+`: `已成功终止进程 %d
+`,
+	`Failed to kill process %d: %s
+`: `终止进程 %d 失败: %s
+`,
+	"Verified: all released ports are free": "验证通过: 释放的端口均已空闲",
+	`Verification failed: port %s
+`: `验证失败: 端口 %s
+`,
+	"could not be verified: %v":                                            "无法验证: %v",
+	"%d/%s is still held by PID %d":                                        "%d/%s 仍被 PID %d 占用",
+	"%d/%s is now held by PID %d (%s), possibly restarted by a supervisor": "%d/%s 现在被 PID %d (%s) 占用，可能被守护进程重新启动",
+	`
+Summary: %d succeeded, %d failed, %d skipped
+`: `
+汇总: 成功 %d 个，失败 %d 个，跳过 %d 个
+`,
+	`
+%d process(es) could not be killed for lack of privileges`: `
+%d 个进程因权限不足无法终止`,
+	"Retry them with elevated privileges?":             "是否提权后重试？",
+	"failed to kill %d of %d process(es)":              "%[2]d 个进程中有 %[1]d 个终止失败",
+	"failed to kill %d process(es): permission denied": "%d 个进程终止失败: 权限不足",
+	"failed to kill %d process(es)":                    "%d 个进程终止失败",
+	"%d port(s) still in use after release":            "释放后仍有 %d 个端口被占用",
+	`    Command: %s
+`: `    命令:   %s
+`,
+	`    Ports:   %s
+`: `    端口:   %s
+`,
+	`    Protected: %s, will not be killed
+`: `    受保护: %s，不会被终止
+`,
+	`    Name:    %s
+`: `    名称:   %s
+`,
+	`    Path:    %s
+`: `    路径:   %s
+`,
+	`
+Kill these processes? (y/N): `: `
+是否终止这些进程？(y/N): `,
+	`
+Could not read input`: `
+无法读取输入`,
+	`
+Select processes to kill [1-%d] (e.g. 1,3-4; a=all, n=none, ?=details): `: `
+选择要终止的进程 [1-%d]（如 1,3-4；a=全部，n=取消，?=详情）: `,
+	`Invalid selection: %v
+`: `无效的选择: %v
+`,
+	"%d/%s %s held by PID %d (%s)":                                        "%d/%s %s 被 PID %d (%s) 占用",
+	"Port %d is held by a running process":                                "端口 %d 被运行中的进程占用",
+	"Stop the service, or run `portreleasor release %d` to terminate it.": "停止该服务，或运行 `portreleasor release %d` 终止该进程。",
+	"Port %d is bound but its owning process is not visible":              "端口 %d 已被绑定，但看不到其所属进程",
+	"Re-run as root/Administrator to resolve the owner. If it is still missing, the socket belongs to the kernel (see the other findings).": "以 root 或管理员身份重新运行以识别所属进程。如果仍然没有，该套接字属于内核（见其他诊断结果）。",
+	"explain takes a single port, got %q": "explain 只接受单个端口，收到 %q",
+	`Diagnosing port %d...
+
+`: `正在诊断端口 %d...
+
+`,
+	"Bind test:": "绑定测试:",
+	"available":  "可用",
+	`
+Could not list sockets: %s
+`: `
+无法列出套接字: %s
+`,
+	`
+No cause found for port %d being unavailable.
+`: `
+没有找到端口 %d 不可用的原因。
+`,
+	`
+Port %d is available, nothing to explain.
+`: `
+端口 %d 可用，无需诊断。
+`,
+	`    Remediation: %s
+`: `    处理建议: %s
+`,
+	`
+Note: namespace, TIME_WAIT, reserved-port, privileged-port and kernel-socket checks are only available on Linux`: `
+注意: 网络命名空间、TIME_WAIT、保留端口、特权端口和内核套接字检查仅在 Linux 上可用`,
+	`
+Privileges (unprivileged):`: `
+权限（未提权）:`,
+	`
+Privileges (elevated):`: `
+权限（已提权）:`,
+	"missing": "缺少",
+	"held":    "拥有",
+	`  %-18s %s needed to %s
+`: `  %-18s %s 用于%s
+`,
+	"no socket collector works on this host": "本机没有可用的套接字采集器",
+	" (scans cached for %s)":                 "（扫描结果缓存 %s）",
+	"none":                                   "无",
+	`Platform:   %s
+`: `平台:       %s
+`,
+	`Manager:    %s
+`: `管理器:     %s
+`,
+	`Collector:  %s
+`: `采集器:     %s
+`,
+	`    error: %s
+`: `    错误: %s
+`,
+	`    version check failed: %s
+`: `    获取版本失败: %s
+`,
+	`
+Environment:`: `
+运行环境:`,
+	"ok":      "正常",
+	"failed":  "失败",
+	"unknown": "未知",
+	"no":      "否",
+	"off":     "关闭",
+	"All listeners are allowed by the policy": "所有监听端口均符合策略",
+	`Guarding listeners with %d allow rule(s), rescanning every %s
+`: `按 %d 条白名单规则监控监听端口，每 %s 扫描一次
+`,
+	`Violations are released after %s
+`: `违规的监听者将在 %s 后被释放
+`,
+	"scan failed: %s":                                    "扫描失败: %s",
+	"failed to read policy: %v":                          "读取策略失败: %v",
+	"invalid policy %s: %v":                              "无效的策略 %s: %v",
+	"invalid interval: %v":                               "无效的 interval: %v",
+	"invalid grace_period: %v":                           "无效的 grace_period: %v",
+	"allow rule %d: %v":                                  "白名单规则 %d: %v",
+	"allow rule %d: protocol must be tcp or udp, got %q": "白名单规则 %d: 协议必须是 tcp 或 udp，收到 %q",
+	"allow rule %d: invalid address %q":                  "白名单规则 %d: 无效的地址 %q",
+	"allow rule %d: invalid process pattern %q":          "白名单规则 %d: 无效的进程模式 %q",
+	"must be positive, got %s":                           "必须为正数，收到 %s",
+	"violation: %s":                                      "违规: %s",
+	"resolved: %s":                                       "已解除: %s",
+	"not releasing %s: %s":                               "不释放 %s: %s",
+	"failed to release %s: %s":                           "释放 %s 失败: %s",
+	"released: %s":                                       "已释放: %s",
+	"webhook failed: %v":                                 "webhook 调用失败: %v",
+	"webhook failed: %s":                                 "webhook 调用失败: %s",
+	", user %s":                                          "，用户 %s",
+	"%s on %s by %s":                                     "%s（%s）由 %s 占用",
+	"interval must be positive, got %s":                  "interval 必须为正数，收到 %s",
+	"refusing to listen on non-loopback address %s without a token": "未设置令牌，拒绝监听非回环地址 %s",
+	"Warning: no token set, POST /release is disabled":              "警告: 未设置令牌，POST /release 已禁用",
+	`Serving %s on http://%s
+`: `%s 服务运行在 http://%s
+`,
+	`Comparing %s (%s) with %s
+
+`: `比较 %s (%s) 与 %s
+
+`,
+	"live state":     "当前状态",
+	"No differences": "没有差异",
+	`+ %-12s %-24s opened by %s
+`: `+ %-12s %-24s 由 %s 打开
+`,
+	`- %-12s %-24s closed (was %s)
+`: `- %-12s %-24s 已关闭（原为 %s）
+`,
+	`~ %-12s %-24s owner changed: %s -> %s
+`: `~ %-12s %-24s 所属进程变化: %s -> %s
+`,
+	`
+Summary: %d opened, %d closed, %d changed
+`: `
+汇总: 新开放 %d 个，已关闭 %d 个，变化 %d 个
+`,
+	"failed to encode snapshot: %v":          "快照编码失败: %v",
+	"failed to save snapshot: %v":            "保存快照失败: %v",
+	"failed to read snapshot: %v":            "读取快照失败: %v",
+	"invalid snapshot %s: %v":                "无效的快照 %s: %v",
+	"snapshot %s has unsupported version %d": "快照 %s 的版本 %d 不受支持",
+	`%s└─ PID %d  %s (child of %d)
+`: `%s└─ PID %d  %s（%d 的子进程）
+`,
+	`%s  (no sockets)
+`: `%s  （没有套接字）
+`,
+	"failed to list processes: %v": "列出进程失败: %v",
+	`No process found matching %q
+`: `没有找到匹配 %q 的进程
+`,
+	"no process found matching %q": "没有找到匹配 %q 的进程",
+	`
+%d socket(s) held by %d process(es)
+`: `
+%[2]d 个进程共持有 %[1]d 个套接字
+`,
+	"unsupported list type %q (supported: %s, %s)": "不支持的列表类型 %q（支持: %s、%s）",
+	"failed to read standard input: %v":            "读取标准输入失败: %v",
+	"no ports or PIDs on standard input":           "标准输入中没有端口或进程ID",
+	"invalid JSON input: %v":                       "无效的 JSON 输入: %v",
+	"invalid port record: %v":                      "无效的端口记录: %v",
+	"invalid port record: missing %q":              "无效的端口记录: 缺少 %q",
+	"invalid PID '%s'":                             "无效的进程ID '%s'",
+	`Skipped stale entry %s: %s
+`: `已跳过过期的条目 %s: %s
+`,
+	"no longer in use":                                 "已不再被占用",
+	"now held by PID %d (%s)":                          "现在被 PID %d (%s) 占用",
+	"PID %d now runs %s":                               "PID %d 现在运行的是 %s",
+	"PID %d was reused by a process started at %s":     "PID %d 已被 %s 启动的进程复用",
+	"None of the piped entries matches the live state": "管道输入的条目均与当前实时状态不符",
+	"no piped entry matches the live state":            "管道输入的条目均与当前实时状态不符",
+	"Processes using the piped ports:":                 "占用管道输入端口的进程:",
+	// 没有 PID 的记录
+	"no PID recorded, use --force with a bind address to release it": "未记录进程ID，需要 --force 并指定绑定地址才能释放",
+	"held by several processes (PID %s), specify the PID":            "被多个进程占用 (PID %s)，请指定进程ID",
+
+	// 平台实现
+	"%s timed out after %s":         "%s 在 %s 后超时",
+	"failed to kill process %d: %w": "终止进程 %d 失败: %w",
+	"failed to find process %d: %w": "找不到进程 %d: %w",
+	"failed to execute %s: %w":      "执行 %s 失败: %w",
+	"failed to parse %s output: %w": "解析 %s 的输出失败: %w",
+	"%s; using %s instead":          "%s；改用 %s",
+	"%s failed: %w":                 "%s 失败: %w",
+	"%s timed out":                  "%s 超时",
+	"no socket collector available": "没有可用的套接字采集器",
+	"failed to list sockets (%s)":   "列出套接字失败（%s）",
+
+	// 进程详情
+	"failed to get process path: %v":              "获取进程路径失败: %v",
+	"process path not found":                      "未找到进程路径",
+	"failed to get process command line: %v":      "获取进程命令行失败: %v",
+	"process command line not found":              "未找到进程命令行",
+	"failed to get process working directory: %v": "获取进程工作目录失败: %v",
+	"process working directory not found":         "未找到进程工作目录",
+	"process working directory is %w on Windows":  "Windows 上获取进程工作目录: %w",
+	"failed to get process start time: %v":        "获取进程启动时间失败: %v",
+	"failed to parse process start time: %v":      "解析进程启动时间失败: %v",
+	"process start time not found":                "未找到进程启动时间",
+	"failed to get process user: %v":              "获取进程用户失败: %v",
+	"process user not found":                      "未找到进程用户",
+	"failed to get process cgroup: %v":            "获取进程 cgroup 失败: %v",
+	"unexpected format of /proc/%d/stat":          "/proc/%d/stat 格式异常",
+	"unexpected format of /proc/%d/stat: %v":      "/proc/%d/stat 格式异常: %v",
+	"failed to read /proc/stat: %v":               "读取 /proc/stat 失败: %v",
+	"invalid btime in /proc/stat: %v":             "/proc/stat 中的 btime 无效: %v",
+	"btime not found in /proc/stat":               "/proc/stat 中未找到 btime",
+
+	"none of sudo, doas or pkexec is installed; run as root instead":                                                "未安装 sudo、doas 或 pkexec，请以 root 身份运行",
+	"elevation is not supported on Windows; run from an Administrator prompt instead":                               "Windows 上不支持提权，请在管理员命令提示符中运行",
+	"identify the owners of other users' sockets and read their executables, command lines and working directories": "识别其他用户套接字的所属进程，并读取其可执行文件、命令行和工作目录",
+	"terminate processes owned by other users":                                                                      "终止其他用户的进程",
+	"inspect the sockets of other network namespaces, e.g. containers":                                              "查看其他网络命名空间（如容器）中的套接字",
+	"see the sockets of other users' processes and terminate them":                                                  "查看并终止其他用户进程的套接字",
+	"read the paths of service processes and terminate processes of other users":                                    "读取服务进程的路径并终止其他用户的进程",
+	"socket collector":                          "套接字采集器",
+	"fallback socket collector":                 "备用套接字采集器",
+	"used for socket discovery":                 "用于发现套接字",
+	"not used while an earlier collector works": "前面的采集器可用时不使用",
+	"skipped, the next collector is tried":      "已跳过，尝试下一个采集器",
+	"owner resolver":                            "所属进程解析",
+	"owner resolver, process details":           "所属进程解析、进程详情",
+	"sock_diag, queried by ss":                  "sock_diag，由 ss 查询",
+	"process list":                              "进程列表",
+	"owners the socket collector cannot attribute are only looked up in /proc":                             "采集器无法识别的所属进程只能从 /proc 查找",
+	"process names, paths and details cannot be read":                                                      "无法读取进程名称、路径和详情",
+	"ss falls back to reading /proc/net, which is slower":                                                  "ss 会回退到读取 /proc/net，速度较慢",
+	"who cannot include child processes":                                                                   "who 无法包含子进程",
+	"processes of other users are hidden from non-root users, so their sockets show no owner":              "非root用户看不到其他用户的进程，其套接字不显示所属进程",
+	"ports opened by Windows processes are not visible; run the Windows build to see them":                 "看不到 Windows 进程打开的端口，请使用 Windows 版本查看",
+	"the socket tables are emulated and may be incomplete; ports of Windows processes are not visible":     "套接字表是模拟的，可能不完整；看不到 Windows 进程的端口",
+	"only the sockets and processes of this container are visible, unless it shares the host's namespaces": "只能看到本容器的套接字和进程，除非与主机共享命名空间",
+	"root here is not root on the host; processes of unmapped users cannot be inspected or killed":         "此处的 root 不是主机上的 root；无法查看或终止未映射用户的进程",
+	"cannot compare with the namespace of PID 1":                                                           "无法与 PID 1 的命名空间比较",
+	"differs from the namespace of PID 1; sockets of other namespaces are only checked by explain":         "与 PID 1 的命名空间不同；其他命名空间的套接字只由 explain 检查",
+	"cannot read /proc/mounts":                                                   "无法读取 /proc/mounts",
+	"%s %s:%d (no inode, owned by the kernel)":                                   "%s %s:%d（没有 inode，属于内核）",
+	"registered with rpcbind as %s":                                              "已在 rpcbind 中注册为 %s",
+	"Port %d is held by a kernel socket (typically NFS/RPC: nfsd, lockd, statd)": "端口 %d 被内核套接字占用（通常为 NFS/RPC: nfsd、lockd、statd）",
+	"Kernel sockets cannot be released by killing a process. Stop the service that owns them (e.g. systemctl stop nfs-server rpc-statd), or pin lockd to other ports via the fs.nfs.nlm_tcpport / fs.nfs.nlm_udpport sysctls.": "内核套接字无法通过终止进程释放。请停止其所属服务（如 systemctl stop nfs-server rpc-statd），或通过 fs.nfs.nlm_tcpport / fs.nfs.nlm_udpport 内核参数将 lockd 固定到其他端口。",
+	"Port %d is inside the ephemeral range %d-%d and is used by outgoing connection(s)": "端口 %d 位于临时端口范围 %d-%d 内，正被出站连接使用",
+	"The kernel picked this port as the source port of a client connection. Add it to net.ipv4.ip_local_reserved_ports (sysctl -w net.ipv4.ip_local_reserved_ports=%d) so it is never handed out again, or move the service out of %d-%d.": "内核将此端口选作客户端连接的源端口。将其加入 net.ipv4.ip_local_reserved_ports（sysctl -w net.ipv4.ip_local_reserved_ports=%d）以免再被分配，或将服务移出 %d-%d。",
+	"Port %d is listed in net.ipv4.ip_local_reserved_ports (%s)": "端口 %d 列在 net.ipv4.ip_local_reserved_ports 中（%s）",
+	"reserved ports: %s": "保留端口: %s",
+	"Reserved ports are never chosen for automatic (port 0) binds, but an explicit bind() still works. Configure the application with the port explicitly, or remove it from the sysctl if the reservation is stale.": "自动绑定（端口 0）不会选择保留端口，但显式 bind() 仍然有效。请在应用中显式配置该端口，或在保留已过时的情况下将其从内核参数中移除。",
+	"Port %d is below net.ipv4.ip_unprivileged_port_start (%d) and the current user lacks CAP_NET_BIND_SERVICE":                                                                                                       "端口 %d 低于 net.ipv4.ip_unprivileged_port_start（%d），且当前用户没有 CAP_NET_BIND_SERVICE",
+	"bind() fails with \"permission denied\" for unprivileged processes":                                                                                                                                              "非特权进程的 bind() 会因 \"permission denied\" 失败",
+	"Run the service as root, grant the capability with `sudo setcap cap_net_bind_service=+ep <binary>` (or AmbientCapabilities= in a systemd unit), or lower net.ipv4.ip_unprivileged_port_start.":                   "以 root 身份运行服务，用 `sudo setcap cap_net_bind_service=+ep <binary>`（或 systemd 单元中的 AmbientCapabilities=）授予该能力，或调低 net.ipv4.ip_unprivileged_port_start。",
+	"%s %s:%d in %s (namespace of PID %d %s)":                                                "%s %s:%d 位于 %s（PID %d %s 的命名空间）",
+	"Could not inspect the network namespaces of %d process(es)":                             "无法检查 %d 个进程的网络命名空间",
+	"Re-run as root to check whether a container or other network namespace holds the port.": "以 root 身份重新运行，以检查是否有容器或其他网络命名空间占用该端口。",
+	"missing %s, needed to %s":                                                               "缺少 %s，用于%s",
+	"Port %d is bound inside another network namespace":                                      "端口 %d 在另一个网络命名空间中被绑定",
+	"The owner runs in a container or `ip netns` namespace and is invisible to ss/netstat here. Inspect it with `nsenter -t <pid> -n ss -tulpn`, and stop the container or namespace service that publishes the port.": "所属进程运行在容器或 `ip netns` 命名空间中，此处的 ss/netstat 看不到它。用 `nsenter -t <pid> -n ss -tulpn` 查看，并停止发布该端口的容器或命名空间服务。",
+	"%d connection(s) on port %d are in TIME_WAIT": "端口 %[2]d 上有 %[1]d 个连接处于 TIME_WAIT 状态",
+	"TIME_WAIT sockets have no owning process and cannot be killed; they expire after about 60s. Set SO_REUSEADDR on the listening socket before bind() so restarts are not blocked by them.": "TIME_WAIT 套接字没有所属进程，无法终止，约 60 秒后过期。在 bind() 前为监听套接字设置 SO_REUSEADDR，以免重启时被其阻塞。",
+
+	// 参数解析
+	"invalid port range '%s': %v":                                        "无效的端口范围 '%s': %v",
+	"invalid port number '%s': %v":                                       "无效的端口号 '%s': %v",
+	"port %d out of range (1-65535)":                                     "端口号 %d 超出范围 (1-65535)",
+	"malformed port range":                                               "端口范围格式错误",
+	"invalid start port: %v":                                             "无效的起始端口: %v",
+	"invalid end port: %v":                                               "无效的结束端口: %v",
+	"port range out of range (1-65535)":                                  "端口范围超出有效范围 (1-65535)",
+	"start port is greater than end port":                                "起始端口不能大于结束端口",
+	"invalid number '%s'":                                                "无效的编号 '%s'",
+	"start number is greater than end number '%s'":                       "起始编号不能大于结束编号 '%s'",
+	"number '%s' out of range (1-%d)":                                    "编号 '%s' 超出范围 (1-%d)",
+	"no number selected":                                                 "未选择任何编号",
+	"invalid protocol %q in '%s': must be tcp or udp":                    "'%[2]s' 中的协议 %[1]q 无效: 必须是 tcp 或 udp",
+	"invalid address in '%s': expected [address]:port":                   "'%s' 中的地址无效: 格式应为 [地址]:端口",
+	"invalid IPv6 address %q in '%s'":                                    "'%[2]s' 中的 IPv6 地址 %[1]q 无效",
+	"IPv6 address in '%s' must be enclosed in brackets, e.g. [::1]:9000": "'%s' 中的 IPv6 地址必须放在方括号中，如 [::1]:9000",
+	"missing address before the port in '%s'":                            "'%s' 中端口前缺少地址",
+	"unknown address or interface %q":                                    "未知的地址或网卡 %q",
+	"failed to get the addresses of interface %s: %v":                    "获取网卡 %s 的地址失败: %v",
+
+	// 语言选择
+	"unsupported language %q (supported: %s)": "不支持的语言 %q（支持: %s）",
+}
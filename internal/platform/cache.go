@@ -0,0 +1,155 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"portreleasor/internal/types"
+)
+
+// DefaultCacheTTL is how long the shared manager reuses a snapshot of the sockets or
+// processes. It is shorter than the scan intervals of serve and guard, so every tick
+// rescans while the queries of one tick or one burst of HTTP requests share a scan.
+const DefaultCacheTTL = time.Second
+
+// CachedManager wraps a PlatformManager and reuses its snapshots of the sockets and
+// processes for the TTL. Concurrent queries for a snapshot that is being taken wait for
+// it instead of scanning again. Killing a process invalidates the cache, and Invalidate
+// does so explicitly. It is safe for concurrent use if the wrapped manager is.
+type CachedManager struct {
+	PlatformManager
+
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is one snapshot, or a scan in progress until done is closed
+type cacheEntry struct {
+	done    chan struct{}
+	value   interface{}
+	err     error
+	expires time.Time
+}
+
+// NewCachedManager returns manager with its snapshots cached for ttl
+func NewCachedManager(manager PlatformManager, ttl time.Duration) *CachedManager {
+	return &CachedManager{
+		PlatformManager: manager,
+		ttl:             ttl,
+		entries:         make(map[string]*cacheEntry),
+	}
+}
+
+// Invalidate discards the cached snapshots, so the next query rescans the system
+func (c *CachedManager) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 进行中的扫描仍交给正在等待的调用方，但不再被缓存
+	c.entries = make(map[string]*cacheEntry)
+}
+
+// Invalidate discards the snapshots cached by the manager, if it caches any
+func Invalidate(manager PlatformManager) {
+	if cached, ok := manager.(interface{ Invalidate() }); ok {
+		cached.Invalidate()
+	}
+}
+
+// GetPortConnections returns the cached listening sockets with their owners
+func (c *CachedManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return c.sockets(ctx, "connections", c.PlatformManager.GetPortConnections)
+}
+
+// GetListeningSockets returns the cached listening sockets without resolved owners
+func (c *CachedManager) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return c.sockets(ctx, "listening", c.PlatformManager.GetListeningSockets)
+}
+
+// GetAllSockets returns the cached listening and connected sockets
+func (c *CachedManager) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return c.sockets(ctx, "all", c.PlatformManager.GetAllSockets)
+}
+
+// ListProcesses returns the cached process list
+func (c *CachedManager) ListProcesses(ctx context.Context) ([]types.ProcessInfo, error) {
+	value, err := c.load(ctx, "processes", func(ctx context.Context) (interface{}, error) {
+		return c.PlatformManager.ListProcesses(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]types.ProcessInfo(nil), value.([]types.ProcessInfo)...), nil
+}
+
+// KillProcessByPID kills the process and invalidates the cache, whether or not the
+// kill succeeded, since the process may have exited either way
+func (c *CachedManager) KillProcessByPID(ctx context.Context, pid int) error {
+	defer c.Invalidate()
+	return c.PlatformManager.KillProcessByPID(ctx, pid)
+}
+
+// sockets loads a socket snapshot and returns a copy, since callers such as
+// ResolveProcesses modify the sockets and their statuses in place
+func (c *CachedManager) sockets(ctx context.Context, key string, scan func(context.Context) ([]types.PortInfo, error)) ([]types.PortInfo, error) {
+	value, err := c.load(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return scan(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sockets := append([]types.PortInfo(nil), value.([]types.PortInfo)...)
+	for i := range sockets {
+		sockets[i].Status = maps.Clone(sockets[i].Status)
+	}
+	return sockets, nil
+}
+
+// load returns the snapshot for key, scanning if there is none or it expired. Failed
+// scans are not cached.
+func (c *CachedManager) load(ctx context.Context, key string, scan func(context.Context) (interface{}, error)) (interface{}, error) {
+	for {
+		c.mu.Lock()
+		entry := c.entries[key]
+		if entry == nil || entry.stale() {
+			entry = &cacheEntry{done: make(chan struct{})}
+			c.entries[key] = entry
+			c.mu.Unlock()
+
+			entry.value, entry.err = scan(ctx)
+			entry.expires = time.Now().Add(c.ttl)
+			close(entry.done)
+			return entry.value, entry.err
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// 发起扫描的调用方被取消时，其余调用方各自重新扫描
+		if entry.err != nil && (errors.Is(entry.err, context.Canceled) || errors.Is(entry.err, context.DeadlineExceeded)) {
+			continue
+		}
+		return entry.value, entry.err
+	}
+}
+
+// stale reports whether the entry finished and must not be reused, either because it
+// expired or because its scan failed
+func (e *cacheEntry) stale() bool {
+	select {
+	case <-e.done:
+		return e.err != nil || time.Now().After(e.expires)
+	default:
+		return false
+	}
+}
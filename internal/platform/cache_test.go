@@ -0,0 +1,158 @@
+package platform_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"portreleasor/internal/platform"
+	"portreleasor/internal/platform/platformtest"
+	"portreleasor/internal/types"
+)
+
+// countingManager counts the socket scans of the fake it wraps
+type countingManager struct {
+	*platformtest.FakeManager
+	scans atomic.Int32
+}
+
+func (m *countingManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	m.scans.Add(1)
+	return m.FakeManager.GetPortConnections(ctx)
+}
+
+func newCountingManager(t *testing.T) *countingManager {
+	t.Helper()
+	output, err := os.ReadFile("testdata/ss_tunlp.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &countingManager{FakeManager: &platformtest.FakeManager{
+		Replay:    platformtest.Replay{Format: platformtest.FormatSS, Output: string(output)},
+		Processes: []types.ProcessInfo{{PID: 1001, Name: "sshd"}},
+	}}
+}
+
+func TestCachedManagerReuse(t *testing.T) {
+	counting := newCountingManager(t)
+	cached := platform.NewCachedManager(counting, time.Hour)
+	ctx := context.Background()
+
+	first, err := cached.GetPortConnections(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first[0].ProcessName = "modified"
+
+	second, err := cached.GetPortConnections(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := counting.scans.Load(); got != 1 {
+		t.Fatalf("scans = %d, want 1", got)
+	}
+	if second[0].ProcessName == "modified" {
+		t.Errorf("cached snapshot was modified through a returned slice")
+	}
+
+	cached.Invalidate()
+	if _, err := cached.GetPortConnections(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := counting.scans.Load(); got != 2 {
+		t.Errorf("scans after Invalidate = %d, want 2", got)
+	}
+}
+
+func TestCachedManagerExpires(t *testing.T) {
+	counting := newCountingManager(t)
+	cached := platform.NewCachedManager(counting, 10*time.Millisecond)
+	ctx := context.Background()
+
+	cached.GetPortConnections(ctx)
+	time.Sleep(20 * time.Millisecond)
+	cached.GetPortConnections(ctx)
+
+	if got := counting.scans.Load(); got != 2 {
+		t.Errorf("scans = %d, want 2", got)
+	}
+}
+
+func TestCachedManagerKillInvalidates(t *testing.T) {
+	counting := newCountingManager(t)
+	cached := platform.NewCachedManager(counting, time.Hour)
+	ctx := context.Background()
+
+	cached.GetPortConnections(ctx)
+	if err := cached.KillProcessByPID(ctx, 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	connections, err := cached.GetPortConnections(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, conn := range connections {
+		if conn.PID == 1001 {
+			t.Errorf("killed process still listed on port %d", conn.Port)
+		}
+	}
+}
+
+func TestCachedManagerConcurrent(t *testing.T) {
+	counting := newCountingManager(t)
+	counting.Delay = 50 * time.Millisecond
+	cached := platform.NewCachedManager(counting, time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cached.GetPortConnections(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counting.scans.Load(); got != 1 {
+		t.Errorf("scans = %d, want 1", got)
+	}
+}
+
+func TestCachedManagerCancelledScan(t *testing.T) {
+	counting := newCountingManager(t)
+	counting.Delay = 50 * time.Millisecond
+	cached := platform.NewCachedManager(counting, time.Hour)
+
+	// 发起扫描的调用方被取消后，等待中的调用方应自行重新扫描而不是收到取消错误
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := cached.GetPortConnections(ctx)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := cached.GetPortConnections(context.Background())
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != context.Canceled {
+		t.Errorf("cancelled caller got %v, want context.Canceled", err)
+	}
+	if err := <-waiter; err != nil {
+		t.Errorf("waiting caller got %v, want the result of its own scan", err)
+	}
+	if got := counting.scans.Load(); got != 2 {
+		t.Errorf("scans = %d, want 2", got)
+	}
+}
@@ -0,0 +1,148 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"portreleasor/internal/i18n"
+)
+
+// DefaultTimeout is how long a backend command may run unless configured otherwise
+const DefaultTimeout = 10 * time.Second
+
+// killGrace is how long to wait for a killed backend to exit. A process blocked in an
+// uninterruptible sleep, e.g. lsof on a stale NFS mount, ignores SIGKILL until its I/O
+// returns, so it is abandoned instead of waited for.
+const killGrace = 500 * time.Millisecond
+
+// Timeouts configures how long each backend, i.e. external command such as ss or lsof,
+// may run
+type Timeouts struct {
+	// Default applies to backends without their own timeout, DefaultTimeout if zero
+	Default time.Duration
+	// Backends maps a command name to its timeout
+	Backends map[string]time.Duration
+}
+
+// TimeoutError reports a backend that did not finish within its timeout
+type TimeoutError struct {
+	Backend string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %s", e.Backend, e.Timeout)
+}
+
+// Localize returns the message in the selected language
+func (e *TimeoutError) Localize() string {
+	return i18n.Sprintf("%s timed out after %s", e.Backend, e.Timeout)
+}
+
+type contextKey int
+
+const (
+	timeoutsKey contextKey = iota
+	reporterKey
+)
+
+// WithTimeouts returns a context whose backend commands are bounded by the timeouts
+func WithTimeouts(ctx context.Context, timeouts Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey, timeouts)
+}
+
+// WithReporter returns a context whose backend timeouts and fallbacks are reported to
+// report; without one they are silent
+func WithReporter(ctx context.Context, report func(message string)) context.Context {
+	return context.WithValue(ctx, reporterKey, report)
+}
+
+// reportf reports a degraded backend to the reporter of the context, if any
+func reportf(ctx context.Context, format string, args ...interface{}) {
+	if report, ok := ctx.Value(reporterKey).(func(string)); ok {
+		report(i18n.Sprintf(format, args...))
+	}
+}
+
+// timeoutFor returns the timeout of the backend configured in the context
+func timeoutFor(ctx context.Context, name string) time.Duration {
+	timeouts, _ := ctx.Value(timeoutsKey).(Timeouts)
+	if timeout := timeouts.Backends[name]; timeout > 0 {
+		return timeout
+	}
+	if timeouts.Default > 0 {
+		return timeouts.Default
+	}
+	return DefaultTimeout
+}
+
+// runCommand runs a backend command and returns its standard output. It fails with a
+// *TimeoutError when the backend exceeds its timeout and with ctx.Err() once ctx is done.
+func runCommand(ctx context.Context, name string, args ...string) (*bytes.Buffer, error) {
+	return runCommandEnv(ctx, nil, name, args...)
+}
+
+// runCommandEnv runs a backend command like runCommand with additional environment variables
+func runCommandEnv(ctx context.Context, env []string, name string, args ...string) (*bytes.Buffer, error) {
+	return runBackend(ctx, env, false, name, args...)
+}
+
+// runCommandCombined runs a backend command like runCommand and returns its standard
+// output and standard error interleaved
+func runCommandCombined(ctx context.Context, name string, args ...string) (*bytes.Buffer, error) {
+	return runBackend(ctx, nil, true, name, args...)
+}
+
+// runBackend runs a backend command for runCommandEnv and runCommandCombined
+func runBackend(ctx context.Context, env []string, combined bool, name string, args ...string) (*bytes.Buffer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	timeout := timeoutFor(ctx, name)
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if combined {
+		cmd.Stderr = &out
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-cmdCtx.Done():
+		// CommandContext 会杀死超时的进程，无法退出的进程不再等待
+		select {
+		case err = <-done:
+		case <-time.After(killGrace):
+			err = cmdCtx.Err()
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if cmdCtx.Err() == context.DeadlineExceeded {
+		timeoutErr := &TimeoutError{Backend: name, Timeout: timeout}
+		reportf(ctx, "%s timed out after %s", name, timeout)
+		return nil, timeoutErr
+	}
+	return &out, err
+}
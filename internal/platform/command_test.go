@@ -0,0 +1,82 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunCommandTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	var reports []string
+	ctx := WithReporter(context.Background(), func(message string) { reports = append(reports, message) })
+	ctx = WithTimeouts(ctx, Timeouts{Backends: map[string]time.Duration{"sleep": 50 * time.Millisecond}})
+
+	start := time.Now()
+	_, err := runCommand(ctx, "sleep", "5")
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Backend != "sleep" {
+		t.Fatalf("runCommand() error = %v, want a timeout of sleep", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("runCommand() returned after %s", elapsed)
+	}
+	if len(reports) != 1 || reports[0] != "sleep timed out after 50ms" {
+		t.Errorf("reports = %q", reports)
+	}
+}
+
+func TestRunCommandCancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err := runCommand(ctx, "sleep", "5"); !errors.Is(err, context.Canceled) {
+		t.Errorf("runCommand() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTimeoutFor(t *testing.T) {
+	ctx := WithTimeouts(context.Background(), Timeouts{
+		Default:  time.Second,
+		Backends: map[string]time.Duration{"lsof": time.Minute},
+	})
+
+	if got := timeoutFor(ctx, "lsof"); got != time.Minute {
+		t.Errorf("timeoutFor(lsof) = %s, want 1m", got)
+	}
+	if got := timeoutFor(ctx, "ss"); got != time.Second {
+		t.Errorf("timeoutFor(ss) = %s, want 1s", got)
+	}
+	if got := timeoutFor(context.Background(), "ss"); got != DefaultTimeout {
+		t.Errorf("timeoutFor(ss) without configuration = %s, want %s", got, DefaultTimeout)
+	}
+}
+
+func TestBackendVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	versionArgs["sh"] = []string{"-c", "echo 'sh version 5.2.15' >&2; exit 1"}
+	defer delete(versionArgs, "sh")
+	if version, err := backendVersion(context.Background(), "sh"); version != "5.2.15" || err != nil {
+		t.Errorf("backendVersion() = %q, %v, want the version printed to stderr", version, err)
+	}
+
+	versionArgs["sh"] = []string{"-c", "sleep 5"}
+	ctx := WithTimeouts(context.Background(), Timeouts{Backends: map[string]time.Duration{"sh": 50 * time.Millisecond}})
+	var timeoutErr *TimeoutError
+	if version, err := backendVersion(ctx, "sh"); version != "" || !errors.As(err, &timeoutErr) {
+		t.Errorf("backendVersion() = %q, %v, want a timeout", version, err)
+	}
+}
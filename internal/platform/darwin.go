@@ -3,167 +3,74 @@
 package platform
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
+	"context"
 	"os"
-	"os/exec"
-	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
+	"portreleasor/internal/i18n"
 	"portreleasor/internal/types"
 )
 
-// DarwinManager macOS平台实现
-type DarwinManager struct {
-	processNameCache map[int]string
-}
+// DarwinManager macOS平台实现，不保存状态，可并发使用
+type DarwinManager struct{}
 
-// initProcessCache 初始化进程缓存
-func (dm *DarwinManager) initProcessCache() {
-	if dm.processNameCache == nil {
-		dm.processNameCache = make(map[int]string)
+// pipeline 返回macOS的套接字发现流程：lsof 列出套接字，ps 补全进程名称和路径
+func (dm *DarwinManager) pipeline() *pipeline {
+	return &pipeline{
+		collectors: []socketCollector{
+			toolCollector("lsof", fixedArgs("-i", "-P", "-n"), parseLsof),
+		},
+		enrichers: []processEnricher{dm.enrichFromPS},
+		status:    dm.ProcessStatus,
 	}
 }
 
-// getAllProcessNames 批量获取所有进程名称
-func (dm *DarwinManager) getAllProcessNames() error {
-	dm.initProcessCache()
-
-	cmd := exec.Command("ps", "-axo", "pid,comm")
-	var out bytes.Buffer
-	cmd.Stdout = &out
+// enrichFromPS 批量获取进程名称，lsof 的 COMMAND 列会被截断
+func (dm *DarwinManager) enrichFromPS(ctx context.Context, details map[int]*processDetails) error {
+	pids := make([]string, 0, len(details))
+	for pid := range details {
+		pids = append(pids, strconv.Itoa(pid))
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute ps: %v", err)
+	// 仅查询需要的进程；部分进程已退出时 ps 返回非零状态，但仍输出其余进程
+	out, err := runCommand(ctx, "ps", "-o", "pid,comm", "-p", strings.Join(pids, ","))
+	if err != nil && out == nil {
+		return i18n.Errorf("failed to execute %s: %w", "ps", err)
 	}
 
-	scanner := bufio.NewScanner(strings.NewReader(out.String()))
-	// Skip header line
-	if scanner.Scan() {
-		// Skip header
+	names, err := parsePSNames(out)
+	if err != nil {
+		return i18n.Errorf("failed to parse %s output: %w", "ps", err)
 	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
+	for pid, d := range details {
+		name, path := dm.extractProcessNameAndPath(names[pid])
+		if d.Name == "" {
+			d.Name = name
 		}
-
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			pidStr := fields[0]
-			name := fields[1]
-			if pid, err := strconv.Atoi(pidStr); err == nil {
-				dm.processNameCache[pid] = name
-			}
+		if d.Path == "" {
+			d.Path = path
 		}
 	}
-
 	return nil
 }
 
 // GetPortConnections 获取macOS系统端口连接信息
-func (dm *DarwinManager) GetPortConnections() ([]types.PortInfo, error) {
-	// 首先批量获取所有进程信息
-	if err := dm.getAllProcessNames(); err != nil {
-		return nil, fmt.Errorf("failed to get process names: %v", err)
-	}
-
-	cmd := exec.Command("lsof", "-i", "-P", "-n")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to execute lsof: %v", err)
-	}
-
-	var connections []types.PortInfo
-	lines := strings.Split(out.String(), "\n")
-
-	portMap := make(map[string]types.PortInfo) // key: "port:protocol"
-
-	re := regexp.MustCompile(`\s+`)
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "COMMAND") {
-			continue
-		}
-
-		fields := re.Split(line, -1)
-		if len(fields) < 9 {
-			continue
-		}
-
-		processName := fields[0]
-		pidStr := fields[1]
-		protocol := strings.ToUpper(fields[7])
-		address := fields[8]
-
-		// 提取端口号
-		var port int
-		if strings.Contains(address, "->") {
-			// 跳过 outgoing connections
-			continue
-		}
-
-		parts := strings.Split(address, ":")
-		if len(parts) < 2 {
-			continue
-		}
-
-		portStr := parts[len(parts)-1]
-		port, err := strconv.Atoi(portStr)
-		if err != nil {
-			continue
-		}
-
-		pid, err := strconv.Atoi(pidStr)
-		if err != nil {
-			continue
-		}
-
-		// 从缓存获取进程名称
-		cachedName := dm.getProcessNameFromCache(pid)
-		if cachedName != "" {
-			processName = cachedName
-		}
-
-		// 提取进程名称和完整路径
-		displayName, fullPath := dm.extractProcessNameAndPath(processName)
-
-		key := fmt.Sprintf("%d:%s", port, protocol)
-
-		// 创建新的端口信息
-		newInfo := types.PortInfo{
-			Port:        port,
-			Protocol:    protocol,
-			PID:         pid,
-			ProcessName: displayName,
-			ProcessPath: fullPath,
-			LocalAddr:   address,
-			State:       "LISTENING",
-		}
-
-		// 检查是否已存在该端口的记录
-		if _, exists := portMap[key]; exists {
-			// 保持现有的记录（macOS lsof 通常不会重复显示监听端口）
-			continue
-		} else {
-			// 如果不存在，直接添加
-			portMap[key] = newInfo
-		}
-	}
+func (dm *DarwinManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return dm.pipeline().listening(ctx)
+}
 
-	// 将 map 转换为 slice
-	for _, info := range portMap {
-		connections = append(connections, info)
-	}
+// GetListeningSockets 获取macOS系统监听端口，不解析所属进程
+func (dm *DarwinManager) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return dm.pipeline().sockets(ctx, true)
+}
 
-	return connections, nil
+// ResolveProcesses 解析套接字所属进程及其名称和路径
+func (dm *DarwinManager) ResolveProcesses(ctx context.Context, sockets []types.PortInfo) {
+	dm.pipeline().resolve(ctx, sockets)
 }
 
 // extractProcessNameAndPath 从完整路径中提取进程名称和路径
@@ -183,20 +90,11 @@ func (dm *DarwinManager) extractProcessNameAndPath(fullPath string) (string, str
 	return fullPath, ""
 }
 
-// getProcessNameFromCache 从缓存获取进程名称
-func (dm *DarwinManager) getProcessNameFromCache(pid int) string {
-	dm.initProcessCache()
-	if name, exists := dm.processNameCache[pid]; exists {
-		return name
-	}
-	return ""
-}
-
 // KillProcessByPID 在macOS上杀死指定PID的进程
-func (dm *DarwinManager) KillProcessByPID(pid int) error {
+func (dm *DarwinManager) KillProcessByPID(ctx context.Context, pid int) error {
 	proc, err := os.FindProcess(pid)
 	if err != nil {
-		return fmt.Errorf("failed to find process %d: %v", pid, err)
+		return i18n.Errorf("failed to find process %d: %w", pid, err)
 	}
 
 	err = proc.Signal(syscall.SIGTERM)
@@ -204,7 +102,7 @@ func (dm *DarwinManager) KillProcessByPID(pid int) error {
 		// 如果 SIGTERM 失败，尝试 SIGKILL
 		err = proc.Signal(syscall.SIGKILL)
 		if err != nil {
-			return fmt.Errorf("failed to kill process %d: %v", pid, err)
+			return i18n.Errorf("failed to kill process %d: %w", pid, err)
 		}
 	}
 
@@ -212,13 +110,10 @@ func (dm *DarwinManager) KillProcessByPID(pid int) error {
 }
 
 // GetProcessPath 获取macOS进程路径
-func (dm *DarwinManager) GetProcessPath(pid int) (string, error) {
-	cmd := exec.Command("ps", "-p", strconv.Itoa(pid), "-o", "command")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to get process path: %v", err)
+func (dm *DarwinManager) GetProcessPath(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "ps", "-p", strconv.Itoa(pid), "-o", "command")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process path: %v", err)
 	}
 
 	lines := strings.Split(out.String(), "\n")
@@ -229,12 +124,119 @@ func (dm *DarwinManager) GetProcessPath(pid int) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("process path not found")
+	return "", i18n.Errorf("process path not found")
+}
+
+// GetProcessCommandLine 获取macOS进程完整命令行
+func (dm *DarwinManager) GetProcessCommandLine(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "ps", "-ww", "-p", strconv.Itoa(pid), "-o", "args=")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process command line: %v", err)
+	}
+
+	cmdline := strings.TrimSpace(out.String())
+	if cmdline == "" {
+		return "", i18n.Errorf("process command line not found")
+	}
+
+	return cmdline, nil
+}
+
+// GetProcessCwd 获取macOS进程工作目录
+func (dm *DarwinManager) GetProcessCwd(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "lsof", "-a", "-p", strconv.Itoa(pid), "-d", "cwd", "-Fn")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process working directory: %v", err)
+	}
+
+	// -F 输出每行以字段标识开头，n 为文件名
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, "n") {
+			return strings.TrimPrefix(line, "n"), nil
+		}
+	}
+
+	return "", i18n.Errorf("process working directory not found")
+}
+
+// GetProcessStartTime 获取macOS进程启动时间
+func (dm *DarwinManager) GetProcessStartTime(ctx context.Context, pid int) (time.Time, error) {
+	out, err := runCommandEnv(ctx, []string{"LC_ALL=C"}, "ps", "-p", strconv.Itoa(pid), "-o", "lstart=")
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to get process start time: %v", err)
+	}
+
+	// lstart 格式: "Mon Jan  2 15:04:05 2006"，为本地时间
+	startTime, err := time.ParseInLocation("Mon Jan _2 15:04:05 2006", strings.TrimSpace(out.String()), time.Local)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to parse process start time: %v", err)
+	}
+
+	return startTime, nil
+}
+
+// GetProcessUser 获取macOS进程所属用户
+func (dm *DarwinManager) GetProcessUser(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "ps", "-p", strconv.Itoa(pid), "-o", "user=")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process user: %v", err)
+	}
+
+	name := strings.TrimSpace(out.String())
+	if name == "" {
+		return "", i18n.Errorf("process user not found")
+	}
+
+	return name, nil
+}
+
+// GetProcessContainer macOS进程不运行在容器中（Docker Desktop 容器位于虚拟机内）
+func (dm *DarwinManager) GetProcessContainer(ctx context.Context, pid int) (string, error) {
+	return "", nil
+}
+
+// ProcessStatus 判断macOS进程信息无法读取的原因：向进程发送0号信号，ESRCH 说明进程已退出，
+// EPERM 说明进程属于其他用户
+func (dm *DarwinManager) ProcessStatus(ctx context.Context, pid int) types.FieldStatus {
+	switch err := syscall.Kill(pid, 0); err {
+	case syscall.ESRCH:
+		return types.StatusProcessGone
+	case syscall.EPERM:
+		return types.StatusPermissionDenied
+	}
+	return types.StatusUnsupported
+}
+
+// GetAllSockets 获取macOS系统所有套接字（监听和已建立的连接），不做去重
+func (dm *DarwinManager) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return dm.pipeline().all(ctx)
+}
+
+// ListProcesses 获取macOS系统所有进程及其父进程
+func (dm *DarwinManager) ListProcesses(ctx context.Context) ([]types.ProcessInfo, error) {
+	out, err := runCommand(ctx, "ps", "-axo", "pid,ppid,comm")
+	if err != nil {
+		return nil, i18n.Errorf("failed to execute %s: %w", "ps", err)
+	}
+
+	processes, err := parsePS(out)
+	if err != nil {
+		return nil, i18n.Errorf("failed to parse %s output: %w", "ps", err)
+	}
+
+	// macOS 的 comm 是可执行文件完整路径
+	for i := range processes {
+		processes[i].Name, _ = dm.extractProcessNameAndPath(processes[i].Name)
+	}
+
+	return processes, nil
 }
 
 func init() {
 	// 注册macOS管理器
+	// 所有调用方共享同一个实例，短时间内的重复查询复用同一次扫描
+	manager := NewCachedManager(&DarwinManager{}, DefaultCacheTTL)
 	GetPlatformManager = func() PlatformManager {
-		return &DarwinManager{}
+		return manager
 	}
 }
\ No newline at end of file
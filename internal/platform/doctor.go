@@ -0,0 +1,171 @@
+package platform
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Backend is one data source of the platform manager as probed by Doctor
+type Backend struct {
+	// Name is the command or kernel interface, e.g. ss or /proc
+	Name string `json:"name"`
+	// Role is what the manager uses it for
+	Role string `json:"role"`
+	// Version is the version reported by the command, if it has one
+	Version string `json:"version,omitempty"`
+	// VersionError is why the version command failed, if it did
+	VersionError string `json:"version_error,omitempty"`
+	// Available reports whether the probe succeeded
+	Available bool `json:"available"`
+	// Duration is how long the probe took
+	Duration time.Duration `json:"duration_ns"`
+	// Entries is the number of sockets or processes the probe returned
+	Entries int    `json:"entries"`
+	Error   string `json:"error,omitempty"`
+	// Note explains how the result affects the manager
+	Note string `json:"note,omitempty"`
+}
+
+// EnvironmentFact is one property of the host that changes what the tool can see
+type EnvironmentFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Note explains the consequences of the value, empty if there are none
+	Note string `json:"note,omitempty"`
+}
+
+// DoctorReport describes how the platform manager discovers sockets on this host
+type DoctorReport struct {
+	Platform string `json:"platform"`
+	// Manager is the platform manager in use, e.g. LinuxManager
+	Manager string `json:"manager"`
+	// CacheTTL is how long the shared manager reuses a scan, zero if it does not cache
+	CacheTTL time.Duration `json:"cache_ttl_ns"`
+	// Collector is the socket collector check will use, empty if none works
+	Collector   string            `json:"collector"`
+	Backends    []Backend         `json:"backends"`
+	Environment []EnvironmentFact `json:"environment,omitempty"`
+	Elevated    bool              `json:"elevated"`
+	Privileges  []Privilege       `json:"privileges"`
+}
+
+// backendProbe probes one backend and returns the number of entries it found
+type backendProbe struct {
+	name  string
+	role  string
+	probe func(ctx context.Context) (int, error)
+	// note explains a successful probe, failNote a failed one
+	note     string
+	failNote string
+}
+
+// Doctor probes every backend of the manager in the order the manager tries them and
+// reports the host properties that affect their results
+func Doctor(ctx context.Context, manager PlatformManager) *DoctorReport {
+	report := &DoctorReport{
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		Elevated:   Elevated(),
+		Privileges: Privileges(),
+	}
+
+	if cached, ok := manager.(*CachedManager); ok {
+		report.CacheTTL = cached.ttl
+		manager = cached.PlatformManager
+	}
+	report.Manager = strings.TrimPrefix(fmt.Sprintf("%T", manager), "*platform.")
+
+	var p *pipeline
+	switch m := manager.(type) {
+	case interface{ pipeline() *pipeline }:
+		p = m.pipeline()
+	case interface{ Sockets() *Sockets }:
+		p = m.Sockets().p
+	}
+
+	var probes []backendProbe
+	if p != nil {
+		for i, collector := range p.collectors {
+			collector := collector
+			probe := backendProbe{
+				name: collector.name,
+				role: "socket collector",
+				probe: func(ctx context.Context) (int, error) {
+					entries, err := collector.collect(ctx, true)
+					return len(entries), err
+				},
+				failNote: "skipped, the next collector is tried",
+			}
+			if i > 0 {
+				probe.role = "fallback socket collector"
+			}
+			probes = append(probes, probe)
+		}
+	}
+	probes = append(probes, platformProbes(manager)...)
+
+	for _, probe := range probes {
+		if ctx.Err() != nil {
+			break
+		}
+
+		backend := Backend{Name: probe.name, Role: probe.role}
+		start := time.Now()
+		entries, err := probe.probe(ctx)
+		backend.Duration = time.Since(start)
+		backend.Entries = entries
+		backend.Available = err == nil
+		if err != nil {
+			backend.Error = err.Error()
+		}
+		if backend.Available {
+			backend.Note = probe.note
+		} else {
+			backend.Note = probe.failNote
+		}
+		if _, ok := versionArgs[probe.name]; ok {
+			version, err := backendVersion(ctx, probe.name)
+			backend.Version = version
+			if err != nil {
+				backend.VersionError = err.Error()
+			}
+		}
+
+		if strings.HasSuffix(backend.Role, "socket collector") && backend.Available {
+			// 与 pipeline.collect 一致：使用第一个可用的采集器
+			if report.Collector == "" {
+				report.Collector = backend.Name
+				backend.Note = "used for socket discovery"
+			} else {
+				backend.Note = "not used while an earlier collector works"
+			}
+		}
+		report.Backends = append(report.Backends, backend)
+	}
+
+	report.Environment = environmentFacts()
+	return report
+}
+
+// versionRegexp matches the version number in the output of a backend command
+var versionRegexp = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// backendVersion returns the version printed by a backend command, empty if it prints
+// none. lsof prints it to stderr, so both streams are read. Some tools exit with an
+// error status after printing it, so the error is only returned without a version.
+func backendVersion(ctx context.Context, name string) (string, error) {
+	out, err := runCommandCombined(ctx, name, versionArgs[name]...)
+	if out != nil {
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			if version := versionRegexp.FindString(scanner.Text()); version != "" {
+				return version, nil
+			}
+		}
+	}
+	return "", err
+}
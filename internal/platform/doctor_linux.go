@@ -0,0 +1,202 @@
+//go:build linux
+
+package platform
+
+import (
+	"context"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// versionArgs 使各后端命令输出版本号的参数
+var versionArgs = map[string][]string{
+	"ss":      {"-V"},
+	"netstat": {"--version"},
+	"lsof":    {"-v"},
+	"ps":      {"--version"},
+}
+
+// platformProbes 返回套接字采集器之外的Linux后端：
+// 查找所属进程的 lsof 和 /proc，ss 使用的 netlink sock_diag，以及进程列表使用的 ps
+func platformProbes(manager PlatformManager) []backendProbe {
+	return []backendProbe{
+		{
+			name: "lsof",
+			role: "owner resolver",
+			probe: func(ctx context.Context) (int, error) {
+				out, err := runCommand(ctx, "lsof", "-nP", "-i")
+				if err != nil {
+					return 0, err
+				}
+				entries, err := parseLsof(out)
+				return len(entries), err
+			},
+			failNote: "owners the socket collector cannot attribute are only looked up in /proc",
+		},
+		{
+			name: "/proc",
+			role: "owner resolver, process details",
+			probe: func(ctx context.Context) (int, error) {
+				if _, err := os.Stat("/proc/net/tcp"); err != nil {
+					return 0, err
+				}
+				return len(readAllProcNet("/proc/net")), nil
+			},
+			failNote: "process names, paths and details cannot be read",
+		},
+		{
+			name: "netlink",
+			role: "sock_diag, queried by ss",
+			probe: func(ctx context.Context) (int, error) {
+				fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, syscall.NETLINK_INET_DIAG)
+				if err != nil {
+					return 0, err
+				}
+				syscall.Close(fd)
+				return 0, nil
+			},
+			failNote: "ss falls back to reading /proc/net, which is slower",
+		},
+		{
+			name: "ps",
+			role: "process list",
+			probe: func(ctx context.Context) (int, error) {
+				processes, err := manager.ListProcesses(ctx)
+				return len(processes), err
+			},
+			failNote: "who cannot include child processes",
+		},
+	}
+}
+
+// environmentFacts 检查影响可见套接字和进程的Linux环境：
+// /proc 的 hidepid 挂载选项、WSL、容器、用户命名空间和网络命名空间
+func environmentFacts() []EnvironmentFact {
+	return []EnvironmentFact{
+		hidepidFact(),
+		wslFact(),
+		containerFact(),
+		userNamespaceFact(),
+		netNamespaceFact(),
+	}
+}
+
+// hidepidFact 读取 /proc 的 hidepid 挂载选项，启用时非root用户看不到其他用户的进程
+func hidepidFact() EnvironmentFact {
+	fact := EnvironmentFact{Name: "hidepid", Value: "unknown"}
+
+	data, err := os.ReadFile("/proc/mounts")
+	if err != nil {
+		fact.Note = "cannot read /proc/mounts"
+		return fact
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[1] != "/proc" || fields[2] != "proc" {
+			continue
+		}
+
+		fact.Value = "off"
+		for _, option := range strings.Split(fields[3], ",") {
+			if value, ok := strings.CutPrefix(option, "hidepid="); ok && value != "0" && value != "off" {
+				fact.Value = option
+				fact.Note = "processes of other users are hidden from non-root users, so their sockets show no owner"
+			}
+		}
+	}
+	return fact
+}
+
+// wslFact 通过内核版本判断是否运行在WSL中
+func wslFact() EnvironmentFact {
+	fact := EnvironmentFact{Name: "wsl", Value: "no"}
+
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil || !strings.Contains(strings.ToLower(string(data)), "microsoft") {
+		return fact
+	}
+
+	if strings.Contains(string(data), "WSL2") {
+		fact.Value = "WSL 2"
+		fact.Note = "ports opened by Windows processes are not visible; run the Windows build to see them"
+	} else {
+		fact.Value = "WSL 1"
+		fact.Note = "the socket tables are emulated and may be incomplete; ports of Windows processes are not visible"
+	}
+	return fact
+}
+
+// containerFact 通过运行时留下的标记文件、container 环境变量和 cgroup 判断是否运行在容器中
+func containerFact() EnvironmentFact {
+	fact := EnvironmentFact{Name: "container", Value: "no"}
+
+	switch {
+	case fileExists("/.dockerenv"):
+		fact.Value = "docker"
+	case fileExists("/run/.containerenv"):
+		fact.Value = "podman"
+	case os.Getenv("container") != "":
+		fact.Value = os.Getenv("container")
+	default:
+		data, err := os.ReadFile("/proc/self/cgroup")
+		if err != nil {
+			return fact
+		}
+		match := containerCgroupRegexp.FindStringSubmatch(string(data))
+		if match == nil {
+			return fact
+		}
+		fact.Value = match[1]
+	}
+
+	fact.Note = "only the sockets and processes of this container are visible, unless it shares the host's namespaces"
+	return fact
+}
+
+// userNamespaceFact 通过 uid_map 判断是否运行在用户命名空间中，初始命名空间映射全部UID
+func userNamespaceFact() EnvironmentFact {
+	fact := EnvironmentFact{Name: "user namespace", Value: "unknown"}
+
+	data, err := os.ReadFile("/proc/self/uid_map")
+	if err != nil {
+		return fact
+	}
+
+	mapping := strings.Join(strings.Fields(string(data)), " ")
+	if mapping == "0 0 4294967295" {
+		fact.Value = "no"
+		return fact
+	}
+
+	fact.Value = "uid_map " + mapping
+	fact.Note = "root here is not root on the host; processes of unmapped users cannot be inspected or killed"
+	return fact
+}
+
+// netNamespaceFact 比较当前进程与PID 1的网络命名空间
+func netNamespaceFact() EnvironmentFact {
+	fact := EnvironmentFact{Name: "network namespace", Value: "unknown"}
+
+	self, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		return fact
+	}
+	fact.Value = self
+
+	initNS, err := os.Readlink("/proc/1/ns/net")
+	switch {
+	case err != nil:
+		fact.Note = "cannot compare with the namespace of PID 1"
+	case initNS != self:
+		fact.Note = "differs from the namespace of PID 1; sockets of other namespaces are only checked by explain"
+	}
+	return fact
+}
+
+// fileExists 判断文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
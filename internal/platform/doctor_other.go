@@ -0,0 +1,38 @@
+//go:build !linux
+
+package platform
+
+import (
+	"context"
+	"runtime"
+)
+
+// versionArgs 使各后端命令输出版本号的参数，Windows的命令不提供版本号
+var versionArgs = map[string][]string{
+	"lsof": {"-v"},
+}
+
+// platformProbes 返回套接字采集器之外的后端：macOS 上为 ps，Windows 上为 wmic
+func platformProbes(manager PlatformManager) []backendProbe {
+	name := "ps"
+	if runtime.GOOS == "windows" {
+		name = "wmic"
+	}
+
+	return []backendProbe{
+		{
+			name: name,
+			role: "process list",
+			probe: func(ctx context.Context) (int, error) {
+				processes, err := manager.ListProcesses(ctx)
+				return len(processes), err
+			},
+			failNote: "who cannot include child processes",
+		},
+	}
+}
+
+// environmentFacts 命名空间、hidepid 等环境检查目前仅支持Linux
+func environmentFacts() []EnvironmentFact {
+	return nil
+}
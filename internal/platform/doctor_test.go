@@ -0,0 +1,47 @@
+package platform_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"portreleasor/internal/platform"
+	"portreleasor/internal/platform/platformtest"
+)
+
+func TestDoctor(t *testing.T) {
+	output, err := os.ReadFile("testdata/ss_tunlp.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &platformtest.FakeManager{Replay: platformtest.Replay{Format: platformtest.FormatSS, Output: string(output)}}
+
+	report := platform.Doctor(context.Background(), platform.NewCachedManager(fake, time.Minute))
+
+	if report.Manager != "*platformtest.FakeManager" {
+		t.Errorf("Manager = %q, want *platformtest.FakeManager", report.Manager)
+	}
+	if report.CacheTTL != time.Minute {
+		t.Errorf("CacheTTL = %s, want 1m0s", report.CacheTTL)
+	}
+	if report.Collector != platformtest.FormatSS {
+		t.Errorf("Collector = %q, want %q", report.Collector, platformtest.FormatSS)
+	}
+	if len(report.Backends) == 0 || report.Backends[0].Name != platformtest.FormatSS || report.Backends[0].Entries == 0 {
+		t.Fatalf("first backend is not the probed collector: %+v", report.Backends)
+	}
+}
+
+func TestDoctorBrokenCollector(t *testing.T) {
+	fake := &platformtest.FakeManager{Replay: platformtest.Replay{Format: "unknown"}}
+
+	report := platform.Doctor(context.Background(), fake)
+
+	if report.Collector != "" {
+		t.Errorf("Collector = %q, want none", report.Collector)
+	}
+	if backend := report.Backends[0]; backend.Available || backend.Error == "" {
+		t.Errorf("broken collector reported as %+v", backend)
+	}
+}
@@ -0,0 +1,388 @@
+//go:build linux
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/types"
+)
+
+// /proc/net/tcp 中的套接字状态
+const (
+	tcpStateEstablished = "01"
+	tcpStateTimeWait    = "06"
+	tcpStateUnconnected = "07"
+	tcpStateListen      = "0A"
+)
+
+// procNetEntry /proc/net/{tcp,udp}[6] 中的一行
+type procNetEntry struct {
+	Protocol   string
+	LocalAddr  string
+	LocalPort  int
+	RemoteAddr string
+	State      string
+	Inode      string
+}
+
+// isListening 判断套接字是否处于监听状态（TCP LISTEN 或未连接的 UDP）
+func (e procNetEntry) isListening() bool {
+	if strings.HasPrefix(e.Protocol, "UDP") {
+		return e.State == tcpStateUnconnected
+	}
+	return e.State == tcpStateListen
+}
+
+// DiagnosePort 运行Linux内核层面的检查，解释端口为何不可用
+func DiagnosePort(ctx context.Context, port int) []types.Finding {
+	entries := readAllProcNet("/proc/net")
+
+	var findings []types.Finding
+	for _, check := range []func(int, []procNetEntry) *types.Finding{
+		checkTimeWait,
+		func(port int, entries []procNetEntry) *types.Finding { return checkKernelSockets(ctx, port, entries) },
+		checkEphemeralRange,
+		checkReservedPorts,
+		checkPrivilegedPort,
+	} {
+		if finding := check(port, entries); finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+
+	if finding := checkOtherNamespaces(port); finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	return findings
+}
+
+// checkTimeWait 检查端口上是否有处于 TIME_WAIT 的连接
+func checkTimeWait(port int, entries []procNetEntry) *types.Finding {
+	var details []string
+	for _, e := range entries {
+		if e.LocalPort == port && strings.HasPrefix(e.Protocol, "TCP") && e.State == tcpStateTimeWait {
+			details = append(details, fmt.Sprintf("%s %s:%d -> %s", e.Protocol, e.LocalAddr, e.LocalPort, e.RemoteAddr))
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	return &types.Finding{
+		Check:   "time-wait",
+		Summary: i18n.Sprintf("%d connection(s) on port %d are in TIME_WAIT", len(details), port),
+		Details: details,
+		Remediation: i18n.T("TIME_WAIT sockets have no owning process and cannot be killed; they expire after about 60s. " +
+			"Set SO_REUSEADDR on the listening socket before bind() so restarts are not blocked by them."),
+	}
+}
+
+// checkKernelSockets 检查端口是否被内核套接字（如 nfsd、lockd）占用
+func checkKernelSockets(ctx context.Context, port int, entries []procNetEntry) *types.Finding {
+	var details []string
+	for _, e := range entries {
+		if e.LocalPort != port || e.Inode != "0" {
+			continue
+		}
+		if !e.isListening() {
+			continue
+		}
+		details = append(details, i18n.Sprintf("%s %s:%d (no inode, owned by the kernel)", e.Protocol, e.LocalAddr, e.LocalPort))
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	if service := lookupRPCService(ctx, port); service != "" {
+		details = append(details, i18n.Sprintf("registered with rpcbind as %s", service))
+	}
+
+	return &types.Finding{
+		Check:   "kernel-socket",
+		Summary: i18n.Sprintf("Port %d is held by a kernel socket (typically NFS/RPC: nfsd, lockd, statd)", port),
+		Details: details,
+		Remediation: i18n.T("Kernel sockets cannot be released by killing a process. Stop the service that owns them " +
+			"(e.g. systemctl stop nfs-server rpc-statd), or pin lockd to other ports via the " +
+			"fs.nfs.nlm_tcpport / fs.nfs.nlm_udpport sysctls."),
+	}
+}
+
+// checkEphemeralRange 检查端口是否位于临时端口范围且正被出站连接使用
+func checkEphemeralRange(port int, entries []procNetEntry) *types.Finding {
+	low, high, ok := readPortRange("/proc/sys/net/ipv4/ip_local_port_range")
+	if !ok || port < low || port > high {
+		return nil
+	}
+
+	var details []string
+	for _, e := range entries {
+		if e.LocalPort == port && e.State == tcpStateEstablished && strings.HasPrefix(e.Protocol, "TCP") {
+			details = append(details, fmt.Sprintf("%s %s:%d -> %s", e.Protocol, e.LocalAddr, e.LocalPort, e.RemoteAddr))
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	return &types.Finding{
+		Check:   "ephemeral-port",
+		Summary: i18n.Sprintf("Port %d is inside the ephemeral range %d-%d and is used by outgoing connection(s)", port, low, high),
+		Details: details,
+		Remediation: i18n.Sprintf("The kernel picked this port as the source port of a client connection. "+
+			"Add it to net.ipv4.ip_local_reserved_ports (sysctl -w net.ipv4.ip_local_reserved_ports=%d) "+
+			"so it is never handed out again, or move the service out of %d-%d.", port, low, high),
+	}
+}
+
+// checkReservedPorts 检查端口是否在 ip_local_reserved_ports 中
+func checkReservedPorts(port int, entries []procNetEntry) *types.Finding {
+	data, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_reserved_ports")
+	if err != nil {
+		return nil
+	}
+
+	reserved := strings.TrimSpace(string(data))
+	for _, part := range strings.Split(reserved, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		low, high := part, part
+		if bounds := strings.SplitN(part, "-", 2); len(bounds) == 2 {
+			low, high = bounds[0], bounds[1]
+		}
+		start, err1 := strconv.Atoi(low)
+		end, err2 := strconv.Atoi(high)
+		if err1 != nil || err2 != nil || port < start || port > end {
+			continue
+		}
+
+		return &types.Finding{
+			Check:   "reserved-port",
+			Summary: i18n.Sprintf("Port %d is listed in net.ipv4.ip_local_reserved_ports (%s)", port, part),
+			Details: []string{i18n.Sprintf("reserved ports: %s", reserved)},
+			Remediation: i18n.T("Reserved ports are never chosen for automatic (port 0) binds, but an explicit bind() still works. " +
+				"Configure the application with the port explicitly, or remove it from the sysctl if the reservation is stale."),
+		}
+	}
+
+	return nil
+}
+
+// checkPrivilegedPort 检查当前用户是否有权限绑定特权端口
+func checkPrivilegedPort(port int, entries []procNetEntry) *types.Finding {
+	start := 1024
+	if data, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start"); err == nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			start = value
+		}
+	}
+
+	if port >= start || os.Geteuid() == 0 || hasEffectiveCapability(capNetBindService) {
+		return nil
+	}
+
+	return &types.Finding{
+		Check:   "privileged-port",
+		Summary: i18n.Sprintf("Port %d is below net.ipv4.ip_unprivileged_port_start (%d) and the current user lacks CAP_NET_BIND_SERVICE", port, start),
+		Details: []string{i18n.T("bind() fails with \"permission denied\" for unprivileged processes")},
+		Remediation: i18n.T("Run the service as root, grant the capability with `sudo setcap cap_net_bind_service=+ep <binary>` " +
+			"(or AmbientCapabilities= in a systemd unit), or lower net.ipv4.ip_unprivileged_port_start."),
+	}
+}
+
+// checkOtherNamespaces 检查其他网络命名空间（容器、ip netns）中是否有进程监听该端口
+func checkOtherNamespaces(port int) *types.Finding {
+	self, err := os.Readlink("/proc/self/ns/net")
+	if err != nil {
+		return nil
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	inspected := make(map[string]bool)
+	denied := 0
+	var details []string
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		ns, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+		if err != nil {
+			if os.IsPermission(err) {
+				denied++
+			}
+			continue
+		}
+		if ns == self || inspected[ns] {
+			continue
+		}
+		inspected[ns] = true
+
+		for _, e := range readAllProcNet(fmt.Sprintf("/proc/%d/net", pid)) {
+			if e.LocalPort != port || !e.isListening() {
+				continue
+			}
+			details = append(details, i18n.Sprintf("%s %s:%d in %s (namespace of PID %d %s)",
+				e.Protocol, e.LocalAddr, e.LocalPort, ns, pid, readComm(pid)))
+		}
+	}
+
+	if len(details) == 0 {
+		if denied > 0 && os.Geteuid() != 0 {
+			finding := &types.Finding{
+				Check:       "namespace",
+				Summary:     i18n.Sprintf("Could not inspect the network namespaces of %d process(es)", denied),
+				Remediation: i18n.T("Re-run as root to check whether a container or other network namespace holds the port."),
+			}
+			for _, privilege := range MissingPrivileges(OpNamespaces) {
+				finding.Details = append(finding.Details, i18n.Sprintf("missing %s, needed to %s", privilege.Name, i18n.T(privilege.Purpose)))
+			}
+			return finding
+		}
+		return nil
+	}
+
+	return &types.Finding{
+		Check:   "namespace",
+		Summary: i18n.Sprintf("Port %d is bound inside another network namespace", port),
+		Details: details,
+		Remediation: i18n.T("The owner runs in a container or `ip netns` namespace and is invisible to ss/netstat here. " +
+			"Inspect it with `nsenter -t <pid> -n ss -tulpn`, and stop the container or namespace service that publishes the port."),
+	}
+}
+
+// readAllProcNet 读取目录下的 tcp、tcp6、udp、udp6 表
+func readAllProcNet(dir string) []procNetEntry {
+	var entries []procNetEntry
+	for _, name := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		entries = append(entries, readProcNet(dir+"/"+name, strings.ToUpper(name))...)
+	}
+	return entries
+}
+
+// readProcNet 解析 /proc/net/{tcp,udp}[6] 文件
+func readProcNet(path string, protocol string) []procNetEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entries []procNetEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// Skip header line
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		localIP, localPort, ok := decodeProcNetAddr(fields[1])
+		if !ok {
+			continue
+		}
+		remoteIP, remotePort, _ := decodeProcNetAddr(fields[2])
+
+		entries = append(entries, procNetEntry{
+			Protocol:   protocol,
+			LocalAddr:  localIP,
+			LocalPort:  localPort,
+			RemoteAddr: fmt.Sprintf("%s:%d", remoteIP, remotePort),
+			State:      fields[3],
+			Inode:      fields[9],
+		})
+	}
+
+	return entries
+}
+
+// decodeProcNetAddr 解码 /proc/net 中 "0100007F:1F90" 形式的地址
+func decodeProcNetAddr(value string) (string, int, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return "", 0, false
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", 0, false
+	}
+
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != 4 && len(raw) != 16) {
+		return "", 0, false
+	}
+
+	// 地址按32位小端序存储
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	return ip.String(), int(port), true
+}
+
+// readPortRange 读取 "low high" 形式的端口范围
+func readPortRange(path string) (int, int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	low, err1 := strconv.Atoi(fields[0])
+	high, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return low, high, true
+}
+
+// lookupRPCService 通过 rpcinfo 查找注册在该端口上的RPC服务
+func lookupRPCService(ctx context.Context, port int) string {
+	out, err := runCommand(ctx, "rpcinfo", "-p")
+	if err != nil {
+		return ""
+	}
+
+	// rpcinfo -p 输出格式: program vers proto port service
+	for _, line := range strings.Split(out.String(), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 5 && fields[3] == strconv.Itoa(port) {
+			return fields[4]
+		}
+	}
+
+	return ""
+}
+
+// readComm 读取进程名称
+func readComm(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
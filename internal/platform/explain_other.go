@@ -0,0 +1,14 @@
+//go:build !linux
+
+package platform
+
+import (
+	"context"
+
+	"portreleasor/internal/types"
+)
+
+// DiagnosePort 内核层面的端口诊断目前仅支持Linux
+func DiagnosePort(ctx context.Context, port int) []types.Finding {
+	return nil
+}
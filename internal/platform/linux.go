@@ -3,304 +3,159 @@
 package platform
 
 import (
-	"bufio"
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
 	"os/user"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"portreleasor/internal/i18n"
 	"portreleasor/internal/types"
 )
 
-// LinuxManager Linux平台实现
-type LinuxManager struct {
-	processNameCache map[int]string
-	processPathCache map[int]string
-	isRoot          bool
-}
-
-// initProcessCache 初始化进程缓存
-func (lm *LinuxManager) initProcessCache() {
-	if lm.processNameCache == nil {
-		lm.processNameCache = make(map[int]string)
-	}
-	if lm.processPathCache == nil {
-		lm.processPathCache = make(map[int]string)
-	}
-	// 检测是否为root用户
-	if currentUser, err := user.Current(); err == nil {
-		lm.isRoot = currentUser.Uid == "0"
+// LinuxManager Linux平台实现，不保存状态，可并发使用
+type LinuxManager struct{}
+
+// pipeline 返回Linux的套接字发现流程：ss 失败时回退到 netstat，
+// 无法识别所属进程的监听端口依次尝试 lsof 和 /proc/net，进程名称和路径读取自 /proc
+func (lm *LinuxManager) pipeline() *pipeline {
+	return &pipeline{
+		collectors: []socketCollector{
+			toolCollector("ss", linuxSocketArgs, parseSS),
+			toolCollector("netstat", linuxSocketArgs, parseNetstat),
+		},
+		resolvers: []pidResolver{lm.findProcessWithLsof, lm.findProcessFromProcNet},
+		enrichers: []processEnricher{lm.enrichFromProc},
+		unowned:   linuxUnownedStatus(),
+		status:    lm.ProcessStatus,
 	}
 }
 
-// getAllProcessInfo 批量获取所有进程信息（名称和路径）
-func (lm *LinuxManager) getAllProcessInfo() error {
-	lm.initProcessCache()
-
-	cmd := exec.Command("ps", "-axo", "pid,comm")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute ps: %v", err)
+// linuxUnownedStatus 非root用户看不到其他用户进程的套接字所有者；
+// root仍无法识别的是内核套接字（如nfsd），本就没有所属进程
+func linuxUnownedStatus() types.FieldStatus {
+	if os.Geteuid() != 0 {
+		return types.StatusPermissionDenied
 	}
+	return ""
+}
 
-	scanner := bufio.NewScanner(strings.NewReader(out.String()))
-	// Skip header line
-	if scanner.Scan() {
-		// Skip header
+// linuxSocketArgs ss 与 netstat 共用的参数，仅查询监听端口时使用 -l
+func linuxSocketArgs(listening bool) []string {
+	if listening {
+		return []string{"-tunlp"}
 	}
+	return []string{"-tunap"}
+}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
+// GetPortConnections 获取Linux系统端口连接信息
+func (lm *LinuxManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return lm.pipeline().listening(ctx)
+}
 
-		fields := strings.Fields(line)
-		if len(fields) >= 2 {
-			pidStr := fields[0]
-			name := fields[1]
-
-			// 处理WSL中可能被截断的进程名
-			if len(name) > 15 && strings.Contains(name, "(") {
-				// 可能是截断的进程名，尝试获取完整名称
-				if pid, err := strconv.Atoi(pidStr); err == nil {
-					fullName := lm.getProcessName(pid)
-					if fullName != "" && fullName != "Unknown" {
-						lm.processNameCache[pid] = fullName
-					} else {
-						lm.processNameCache[pid] = name
-					}
-					// 尝试获取进程路径
-					lm.cacheProcessPath(pid)
-				}
-			} else {
-				if pid, err := strconv.Atoi(pidStr); err == nil {
-					lm.processNameCache[pid] = name
-					// 尝试获取进程路径
-					lm.cacheProcessPath(pid)
-				}
-			}
-		}
-	}
+// GetListeningSockets 获取Linux系统监听端口，不解析所属进程
+func (lm *LinuxManager) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return lm.pipeline().sockets(ctx, true)
+}
 
-	return nil
+// ResolveProcesses 解析套接字所属进程及其名称和路径
+func (lm *LinuxManager) ResolveProcesses(ctx context.Context, sockets []types.PortInfo) {
+	lm.pipeline().resolve(ctx, sockets)
 }
 
-// GetPortConnections 获取Linux系统端口连接信息
-func (lm *LinuxManager) GetPortConnections() ([]types.PortInfo, error) {
-	// 首先批量获取所有进程信息
-	if err := lm.getAllProcessInfo(); err != nil {
-		return nil, fmt.Errorf("failed to get process info: %v", err)
+// findProcessWithLsof 使用lsof查找监听指定地址和端口的进程，
+// 有多个进程时不猜测，保持为无所有者
+func (lm *LinuxManager) findProcessWithLsof(ctx context.Context, port int, protocol, localAddr string) (int, bool) {
+	// -i 4tcp:8080 只列出同一地址族的套接字，-n -P 保持地址和端口为数字
+	spec := fmt.Sprintf("%s%s:%d", addrFamily(localAddr), strings.ToLower(protocol), port)
+	out, err := runCommand(ctx, "lsof", "-n", "-P", "-i", spec)
+	if err != nil {
+		return 0, false
 	}
 
-	cmd := exec.Command("ss", "-tunlp")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return lm.getPortConnectionsWithNetstat()
+	entries, err := parseLsof(out)
+	if err != nil {
+		return 0, false
 	}
+	return listeningOwner(entries, port, localAddr)
+}
 
-	var connections []types.PortInfo
-	lines := strings.Split(out.String(), "\n")
-
-	re := regexp.MustCompile(`\s+`)
-	portMap := make(map[string]types.PortInfo) // key: "port:protocol"
-
-	for i, line := range lines {
-		if i == 0 {
-			continue
-		}
-
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		fields := re.Split(line, -1)
-
-		// Handle WSL/ss output format variations
-		if len(fields) < 4 {
-			continue
-		}
-
-		protocol := strings.ToUpper(fields[0])
-		state := fields[1]
-		var localAddr string
-		if len(fields) > 4 {
-			localAddr = fields[4]
-		} else {
-			localAddr = fields[3]
-		}
-
-		// Extract port from local address
-		parts := strings.Split(localAddr, ":")
-		if len(parts) < 2 {
-			continue
-		}
-
-		portStr := parts[len(parts)-1]
-		port, err := strconv.Atoi(portStr)
+// findProcessFromProcNet 从/proc/net中查找监听指定地址和端口的套接字，再通过inode查找进程，
+// 有多个进程时不猜测，保持为无所有者
+func (lm *LinuxManager) findProcessFromProcNet(ctx context.Context, port int, protocol, localAddr string) (int, bool) {
+	// 监听状态：TCP 为 LISTEN(0A)，UDP 为未连接(07)
+	var name, listenState string
+	switch strings.ToUpper(protocol) {
+	case "TCP":
+		name, listenState = "tcp", "0A"
+	case "UDP":
+		name, listenState = "udp", "07"
+	default:
+		return 0, false
+	}
+
+	var files []string
+	switch addrFamily(localAddr) {
+	case "4":
+		files = []string{"/proc/net/" + name}
+	case "6":
+		files = []string{"/proc/net/" + name + "6"}
+	default:
+		files = []string{"/proc/net/" + name, "/proc/net/" + name + "6"}
+	}
+
+	inodes := make(map[string]bool)
+	for _, filename := range files {
+		data, err := os.ReadFile(filename)
 		if err != nil {
 			continue
 		}
 
-		var pid int
-		var processName string
-
-		// Check if we have process info (WSL/ss might not show Process column)
-		if len(fields) >= 6 {
-			for j := 5; j < len(fields); j++ {
-				processInfo := fields[j]
-				if strings.Contains(processInfo, "pid=") {
-					pidMatch := regexp.MustCompile(`pid=(\d+)`).FindStringSubmatch(processInfo)
-					if len(pidMatch) > 1 {
-						pid, _ = strconv.Atoi(pidMatch[1])
-						processName = lm.getProcessNameFromCache(pid)
-						break
-					}
-				}
+		for i, line := range strings.Split(string(data), "\n") {
+			if i == 0 || line == "" {
+				continue // Skip header
 			}
-		}
-
-		// If no PID found, try to infer from state or use alternative method
-		if pid == 0 && strings.Contains(state, "LISTEN") {
-			// For listening ports without PID info, try alternative detection
-			pid, processName = lm.findProcessForPort(port, protocol)
-		}
-
-		key := fmt.Sprintf("%d:%s", port, protocol)
-
-		// 获取进程路径
-		processPath := lm.getProcessPathWithPermissionCheck(pid)
-
-		// 创建新的端口信息
-		newInfo := types.PortInfo{
-			Port:        port,
-			Protocol:    protocol,
-			PID:         pid,
-			ProcessName: processName,
-			ProcessPath: processPath,
-			LocalAddr:   localAddr,
-			State:       "LISTENING",
-		}
-
-		// 检查是否已存在该端口的记录
-		if _, exists := portMap[key]; exists {
-			// 保持现有的记录（ss 通常不会重复显示监听端口）
-			continue
-		} else {
-			// 如果不存在，直接添加
-			portMap[key] = newInfo
-		}
-	}
-
-	// 将 map 转换为 slice
-	for _, info := range portMap {
-		connections = append(connections, info)
-	}
 
-	return connections, nil
-}
+			fields := strings.Fields(line)
+			if len(fields) < 10 || fields[3] != listenState || fields[9] == "0" {
+				continue
+			}
 
-// findProcessForPort 通过其他方法查找使用指定端口的进程
-func (lm *LinuxManager) findProcessForPort(port int, protocol string) (int, string) {
-	// 尝试使用lsof命令
-	cmd := exec.Command("lsof", "-i", fmt.Sprintf(":%d", port))
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err == nil {
-		lines := strings.Split(out.String(), "\n")
-		if len(lines) > 1 {
-			// 跳过标题行
-			for _, line := range lines[1:] {
-				line = strings.TrimSpace(line)
-				if line == "" {
-					continue
-				}
-
-				fields := strings.Fields(line)
-				if len(fields) >= 2 {
-					// lsof输出格式: COMMAND PID USER FD TYPE DEVICE SIZE/OFF NODE NAME
-					pidStr := fields[1]
-					processName := fields[0]
-					if pid, err := strconv.Atoi(pidStr); err == nil {
-						return pid, processName
-					}
-				}
+			// 检查本地地址和端口是否匹配
+			ip, p, ok := parseProcNetAddr(fields[1])
+			if ok && p == port && hostMatches(ip, localAddr) {
+				inodes[fields[9]] = true
 			}
 		}
 	}
-
-	// 如果lsof不可用，尝试解析/proc/net/tcp和udp
-	return lm.findProcessFromProcNet(port, protocol)
-}
-
-// findProcessFromProcNet 从/proc/net中查找进程信息
-func (lm *LinuxManager) findProcessFromProcNet(port int, protocol string) (int, string) {
-	var filename string
-	if strings.ToUpper(protocol) == "TCP" {
-		filename = "/proc/net/tcp"
-	} else if strings.ToUpper(protocol) == "UDP" {
-		filename = "/proc/net/udp"
-	} else {
-		return 0, ""
+	if len(inodes) == 0 {
+		return 0, false
 	}
 
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return 0, ""
-	}
-
-	lines := strings.Split(string(data), "\n")
-	portHex := fmt.Sprintf("%04X", port)
-
-	for i, line := range lines {
-		if i == 0 || line == "" {
-			continue // Skip header
-		}
-
-		fields := strings.Fields(line)
-		if len(fields) < 10 {
-			continue
-		}
-
-		// 检查本地地址端口是否匹配
-		localAddr := fields[1]
-		if strings.HasSuffix(localAddr, ":"+portHex) {
-			// 从inode字段查找进程
-			inode := fields[9]
-			return lm.findProcessByInode(inode)
-		}
-	}
-
-	return 0, ""
+	return singleOwner(lm.findProcessesByInode(inodes))
 }
 
-// findProcessByInode 通过inode查找进程
-func (lm *LinuxManager) findProcessByInode(inode string) (int, string) {
-	if inode == "0" {
-		return 0, ""
-	}
+// findProcessesByInode 通过inode查找持有这些套接字的全部进程
+func (lm *LinuxManager) findProcessesByInode(inodes map[string]bool) map[int]bool {
+	pids := make(map[int]bool)
 
 	// 遍历/proc/[pid]/fd目录查找socket链接
 	procDir, err := os.Open("/proc")
 	if err != nil {
-		return 0, ""
+		return pids
 	}
 	defer procDir.Close()
 
 	entries, err := procDir.Readdirnames(-1)
 	if err != nil {
-		return 0, ""
+		return pids
 	}
 
 	for _, entry := range entries {
@@ -323,193 +178,252 @@ func (lm *LinuxManager) findProcessByInode(inode string) (int, string) {
 			}
 
 			// 检查是否是socket且inode匹配
-			if strings.HasPrefix(linkTarget, "socket:[") && strings.Contains(linkTarget, inode) {
-				processName := lm.getProcessNameFromCache(pid)
-				if processName == "" {
-					processName = lm.getProcessName(pid)
-				}
-				return pid, processName
+			inode, found := strings.CutPrefix(linkTarget, "socket:[")
+			if found && inodes[strings.TrimSuffix(inode, "]")] {
+				pids[pid] = true
+				break
 			}
 		}
 	}
 
-	return 0, ""
+	return pids
+}
+
+// enrichFromProc 从 /proc/<pid>/comm 和 /proc/<pid>/exe 读取进程名称和路径，
+// 无权限或进程已退出时保持为空
+func (lm *LinuxManager) enrichFromProc(ctx context.Context, details map[int]*processDetails) error {
+	for pid, d := range details {
+		if d.Name == "" {
+			if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil {
+				d.Name = strings.TrimSpace(string(data))
+			}
+		}
+		if d.Path == "" {
+			if path, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); err == nil {
+				d.Path = path
+			}
+		}
+	}
+	return nil
 }
 
-// getPortConnectionsWithNetstat 使用netstat作为备用方案
-func (lm *LinuxManager) getPortConnectionsWithNetstat() ([]types.PortInfo, error) {
-	cmd := exec.Command("netstat", "-tunlp")
-	var out bytes.Buffer
-	cmd.Stdout = &out
+// KillProcessByPID 在Linux上杀死指定PID的进程
+func (lm *LinuxManager) KillProcessByPID(ctx context.Context, pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return i18n.Errorf("failed to find process %d: %w", pid, err)
+	}
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to execute netstat: %v", err)
+	err = proc.Signal(syscall.SIGKILL)
+	if err != nil {
+		return i18n.Errorf("failed to kill process %d: %w", pid, err)
 	}
 
-	var connections []types.PortInfo
-	lines := strings.Split(out.String(), "\n")
+	return nil
+}
 
-	re := regexp.MustCompile(`\s+`)
+// GetProcessPath 获取Linux进程路径
+func (lm *LinuxManager) GetProcessPath(ctx context.Context, pid int) (string, error) {
+	exePath := fmt.Sprintf("/proc/%d/exe", pid)
+	path, err := os.Readlink(exePath)
+	if err != nil {
+		return "", i18n.Errorf("failed to get process path: %v", err)
+	}
 
-	for i, line := range lines {
-		if i < 2 {
-			continue
-		}
+	return path, nil
+}
 
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// GetProcessCommandLine 获取Linux进程完整命令行
+func (lm *LinuxManager) GetProcessCommandLine(ctx context.Context, pid int) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return "", i18n.Errorf("failed to get process command line: %v", err)
+	}
 
-		fields := re.Split(line, -1)
-		if len(fields) < 6 {
-			continue
-		}
+	// cmdline 以 NUL 分隔参数
+	cmdline := strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	if cmdline == "" {
+		// 内核线程没有命令行
+		return "", i18n.Errorf("process command line not found")
+	}
 
-		protocol := strings.ToUpper(fields[0])
-		localAddr := fields[3]
+	return cmdline, nil
+}
 
-		parts := strings.Split(localAddr, ":")
-		if len(parts) < 2 {
-			continue
-		}
+// defaultClockTicks 是 USER_HZ 在几乎所有Linux架构上的取值，getconf 不可用时使用
+const defaultClockTicks = 100
+
+// clockTicks 缓存 getconf CLK_TCK 的结果，进程运行期间不会变化
+var clockTicks struct {
+	once sync.Once
+	hz   int64
+}
 
-		portStr := parts[len(parts)-1]
-		port, err := strconv.Atoi(portStr)
+// clockTicksPerSecond 返回 /proc/<pid>/stat 中时间字段的单位（USER_HZ），
+// 通过 getconf CLK_TCK 读取，失败时假定为100
+func clockTicksPerSecond(ctx context.Context) int64 {
+	clockTicks.once.Do(func() {
+		clockTicks.hz = defaultClockTicks
+		out, err := runCommand(ctx, "getconf", "CLK_TCK")
 		if err != nil {
-			continue
+			return
 		}
-
-		var pid int
-		var processName string
-
-		if len(fields) >= 7 {
-			processInfo := fields[6]
-			if processInfo != "-" {
-				pidMatch := regexp.MustCompile(`(\d+)/(.+)`).FindStringSubmatch(processInfo)
-				if len(pidMatch) > 2 {
-					pid, _ = strconv.Atoi(pidMatch[1])
-					processName = pidMatch[2]
-				}
-			} else {
-				// netstat显示"-"表示无法获取PID信息，尝试其他方法
-				pid, processName = lm.findProcessForPort(port, protocol)
-			}
+		if hz, err := strconv.ParseInt(strings.TrimSpace(out.String()), 10, 64); err == nil && hz > 0 {
+			clockTicks.hz = hz
 		}
+	})
+	return clockTicks.hz
+}
 
-		connections = append(connections, types.PortInfo{
-			Port:        port,
-			Protocol:    protocol,
-			PID:         pid,
-			ProcessName: processName,
-			ProcessPath: lm.getProcessPathWithPermissionCheck(pid),
-			LocalAddr:   localAddr,
-			State:       "LISTENING",
-		})
+// GetProcessCwd 获取Linux进程工作目录
+func (lm *LinuxManager) GetProcessCwd(ctx context.Context, pid int) (string, error) {
+	cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+	if err != nil {
+		return "", i18n.Errorf("failed to get process working directory: %v", err)
 	}
 
-	return connections, nil
+	return cwd, nil
 }
 
-// cacheProcessPath 缓存进程路径
-func (lm *LinuxManager) cacheProcessPath(pid int) {
-	if path := lm.getProcessPathFromCache(pid); path == "" {
-		// 尝试从/proc/pid/exe获取路径
-		exePath := fmt.Sprintf("/proc/%d/exe", pid)
-		if path, err := os.Readlink(exePath); err == nil {
-			lm.processPathCache[pid] = path
-		}
+// GetProcessStartTime 获取Linux进程启动时间
+func (lm *LinuxManager) GetProcessStartTime(ctx context.Context, pid int) (time.Time, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to get process start time: %v", err)
 	}
-}
 
-// getProcessPathFromCache 从缓存获取进程路径
-func (lm *LinuxManager) getProcessPathFromCache(pid int) string {
-	lm.initProcessCache()
-	if path, exists := lm.processPathCache[pid]; exists {
-		return path
+	// 进程名可能包含空格和括号，从最后一个 ')' 之后开始解析
+	stat := string(data)
+	idx := strings.LastIndex(stat, ")")
+	if idx < 0 {
+		return time.Time{}, i18n.Errorf("unexpected format of /proc/%d/stat", pid)
 	}
-	return ""
-}
 
-// getProcessPathWithPermissionCheck 根据权限检查获取进程路径显示文本
-func (lm *LinuxManager) getProcessPathWithPermissionCheck(pid int) string {
-	// PID为0的情况
-	if pid == 0 {
-		if lm.isRoot {
-			return "N/A"
-		} else {
-			return "NO PERMISSION"
-		}
+	// ')' 之后第一个字段是 state（第3个字段），starttime 是第22个字段
+	fields := strings.Fields(stat[idx+1:])
+	if len(fields) < 20 {
+		return time.Time{}, i18n.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	startTicks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("unexpected format of /proc/%d/stat: %v", pid, err)
 	}
 
-	// 尝试从缓存获取路径
-	if path := lm.getProcessPathFromCache(pid); path != "" {
-		return path
+	bootTime, err := lm.getBootTime()
+	if err != nil {
+		return time.Time{}, err
 	}
 
-	// 尝试实时获取路径
-	exePath := fmt.Sprintf("/proc/%d/exe", pid)
-	if path, err := os.Readlink(exePath); err == nil {
-		return path
+	// 分开计算整秒和余数，避免长时间运行的系统上纳秒数溢出
+	hz := clockTicksPerSecond(ctx)
+	offset := time.Duration(startTicks/hz)*time.Second + time.Duration(startTicks%hz)*time.Second/time.Duration(hz)
+	return bootTime.Add(offset), nil
+}
+
+// getBootTime 从 /proc/stat 读取系统启动时间
+func (lm *LinuxManager) getBootTime() (time.Time, error) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to read /proc/stat: %v", err)
 	}
 
-	// 无法获取路径，检查权限
-	if _, err := os.Stat(fmt.Sprintf("/proc/%d", pid)); os.IsNotExist(err) {
-		return "PROCESS NOT FOUND"
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "btime ") {
+			btime, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(line, "btime ")), 10, 64)
+			if err != nil {
+				return time.Time{}, i18n.Errorf("invalid btime in /proc/stat: %v", err)
+			}
+			return time.Unix(btime, 0), nil
+		}
 	}
 
-	return "NO PERMISSION"
+	return time.Time{}, i18n.Errorf("btime not found in /proc/stat")
 }
 
-// getProcessNameFromCache 从缓存获取进程名称
-func (lm *LinuxManager) getProcessNameFromCache(pid int) string {
-	lm.initProcessCache()
-	if name, exists := lm.processNameCache[pid]; exists {
-		return name
-	}
-	// 备用方案：从 /proc 读取
-	return lm.getProcessName(pid)
-}
+// containerCgroupRegexp 匹配cgroup路径中的容器运行时和64位容器ID
+var containerCgroupRegexp = regexp.MustCompile(`(docker|libpod|crio|cri-containerd|containerd|kubepods)[^\n]*?([0-9a-f]{64})`)
 
-// getProcessName 获取进程名称（备用方案）
-func (lm *LinuxManager) getProcessName(pid int) string {
-	cmdPath := fmt.Sprintf("/proc/%d/comm", pid)
-	data, err := os.ReadFile(cmdPath)
+// GetProcessUser 获取Linux进程所属用户
+func (lm *LinuxManager) GetProcessUser(ctx context.Context, pid int) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
 	if err != nil {
-		return "Unknown"
+		return "", i18n.Errorf("failed to get process user: %v", err)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "Uid:") {
+			continue
+		}
+
+		// Uid: real effective saved fs
+		fields := strings.Fields(strings.TrimPrefix(line, "Uid:"))
+		if len(fields) == 0 {
+			break
+		}
+		if u, err := user.LookupId(fields[0]); err == nil {
+			return u.Username, nil
+		}
+		return fields[0], nil
 	}
 
-	return strings.TrimSpace(string(data))
+	return "", i18n.Errorf("process user not found")
 }
 
-// KillProcessByPID 在Linux上杀死指定PID的进程
-func (lm *LinuxManager) KillProcessByPID(pid int) error {
-	proc, err := os.FindProcess(pid)
+// GetProcessContainer 通过cgroup获取Linux进程所在的容器
+func (lm *LinuxManager) GetProcessContainer(ctx context.Context, pid int) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
 	if err != nil {
-		return fmt.Errorf("failed to find process %d: %v", pid, err)
+		return "", i18n.Errorf("failed to get process cgroup: %v", err)
 	}
 
-	err = proc.Signal(syscall.SIGKILL)
-	if err != nil {
-		return fmt.Errorf("failed to kill process %d: %v", pid, err)
+	match := containerCgroupRegexp.FindStringSubmatch(string(data))
+	if match == nil {
+		return "", nil
 	}
 
-	return nil
+	runtime := match[1]
+	if runtime == "libpod" {
+		runtime = "podman"
+	}
+	return fmt.Sprintf("%s/%s", runtime, match[2][:12]), nil
 }
 
-// GetProcessPath 获取Linux进程路径
-func (lm *LinuxManager) GetProcessPath(pid int) (string, error) {
-	exePath := fmt.Sprintf("/proc/%d/exe", pid)
-	path, err := os.Readlink(exePath)
+// ProcessStatus 判断Linux进程信息无法读取的原因：/proc/<pid> 不存在说明进程已退出，
+// 无法读取 exe 链接说明进程属于其他用户
+func (lm *LinuxManager) ProcessStatus(ctx context.Context, pid int) types.FieldStatus {
+	if _, err := os.Stat(fmt.Sprintf("/proc/%d", pid)); err != nil {
+		return types.StatusProcessGone
+	}
+	if _, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid)); errors.Is(err, fs.ErrPermission) {
+		return types.StatusPermissionDenied
+	}
+	return types.StatusUnsupported
+}
+
+// GetAllSockets 获取Linux系统所有套接字（监听和已建立的连接），不做去重
+func (lm *LinuxManager) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return lm.pipeline().all(ctx)
+}
+
+// ListProcesses 获取Linux系统所有进程及其父进程
+func (lm *LinuxManager) ListProcesses(ctx context.Context) ([]types.ProcessInfo, error) {
+	out, err := runCommand(ctx, "ps", "-axo", "pid,ppid,comm")
 	if err != nil {
-		return "", fmt.Errorf("failed to get process path: %v", err)
+		return nil, i18n.Errorf("failed to execute %s: %w", "ps", err)
 	}
 
-	return path, nil
+	processes, err := parsePS(out)
+	if err != nil {
+		return nil, i18n.Errorf("failed to parse %s output: %w", "ps", err)
+	}
+
+	return processes, nil
 }
 
 func init() {
+	// 所有调用方共享同一个实例，短时间内的重复查询复用同一次扫描
+	manager := NewCachedManager(&LinuxManager{}, DefaultCacheTTL)
 	GetPlatformManager = func() PlatformManager {
-		return &LinuxManager{}
+		return manager
 	}
 }
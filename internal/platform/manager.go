@@ -1,24 +1,190 @@
 package platform
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
 	"portreleasor/internal/types"
 )
 
-// PlatformManager interface for platform-specific operations
+// PlatformManager interface for platform-specific operations. Every method honours
+// ctx: backend commands stop when it is done, within their configured Timeouts.
+// Implementations must be safe for concurrent use.
 type PlatformManager interface {
 	// GetPortConnections retrieves all port connection information
-	GetPortConnections() ([]types.PortInfo, error)
+	GetPortConnections(ctx context.Context) ([]types.PortInfo, error)
+
+	// GetListeningSockets retrieves one listening socket per port and protocol without
+	// resolving the owning processes, so callers can filter before ResolveProcesses
+	GetListeningSockets(ctx context.Context) ([]types.PortInfo, error)
+
+	// ResolveProcesses fills in the owner of unattributed sockets and the name and path
+	// of every owning process
+	ResolveProcesses(ctx context.Context, sockets []types.PortInfo)
 
 	// KillProcessByPID kills a process by its PID
-	KillProcessByPID(pid int) error
+	KillProcessByPID(ctx context.Context, pid int) error
 
 	// GetProcessPath retrieves the process path
-	GetProcessPath(pid int) (string, error)
+	GetProcessPath(ctx context.Context, pid int) (string, error)
+
+	// GetProcessCommandLine retrieves the full command line of a process
+	GetProcessCommandLine(ctx context.Context, pid int) (string, error)
+
+	// GetProcessCwd retrieves the current working directory of a process
+	GetProcessCwd(ctx context.Context, pid int) (string, error)
+
+	// GetProcessStartTime retrieves the time a process was started
+	GetProcessStartTime(ctx context.Context, pid int) (time.Time, error)
+
+	// GetProcessUser retrieves the name of the user owning a process
+	GetProcessUser(ctx context.Context, pid int) (string, error)
+
+	// GetProcessContainer retrieves the container a process runs in, empty for host processes
+	GetProcessContainer(ctx context.Context, pid int) (string, error)
+
+	// GetAllSockets retrieves every socket, listening and connected, one entry per owning process
+	GetAllSockets(ctx context.Context) ([]types.PortInfo, error)
+
+	// ListProcesses retrieves all processes with their parent PIDs
+	ListProcesses(ctx context.Context) ([]types.ProcessInfo, error)
+
+	// ProcessStatus reports why the details of a process could not be read: it exited,
+	// belongs to a user the caller may not inspect, or is readable but the platform
+	// cannot provide the detail (types.StatusUnsupported)
+	ProcessStatus(ctx context.Context, pid int) types.FieldStatus
 }
 
-// GetPlatformManager returns the platform-specific manager
+// ErrUnsupported is returned, possibly wrapped, for process details the platform
+// cannot provide at all
+var ErrUnsupported = errors.New("not supported")
+
+// GetPlatformManager returns the platform-specific manager, shared by all callers and
+// wrapped in a CachedManager
 var GetPlatformManager = func() PlatformManager {
 	// This will return the appropriate implementation based on the platform
 	// Platform-specific implementations will override this in their init() functions
 	return nil
-}
\ No newline at end of file
+}
+
+// IsListening reports whether a socket returned by GetAllSockets accepts connections
+func IsListening(info types.PortInfo) bool {
+	return socketEntry{State: info.State, RemoteAddr: info.RemoteAddr}.listening()
+}
+
+// uniquePorts keeps one record per port and protocol, preferring listening sockets,
+// sorted by port, protocol and address
+func uniquePorts(infos []types.PortInfo) []types.PortInfo {
+	portMap := make(map[string]types.PortInfo) // key: "port:protocol"
+	for _, info := range infos {
+		key := fmt.Sprintf("%d:%s", info.Port, info.Protocol)
+		if existing, exists := portMap[key]; exists {
+			// 如果新连接是 LISTENING 状态，优先使用；否则保持现有的记录
+			if info.State != "LISTENING" || existing.State == "LISTENING" {
+				continue
+			}
+		}
+		portMap[key] = info
+	}
+
+	connections := make([]types.PortInfo, 0, len(portMap))
+	for _, info := range portMap {
+		connections = append(connections, info)
+	}
+	types.SortPortInfos(connections)
+
+	return connections
+}
+
+// localHost 返回 "addr:port" 形式本地地址中的 IP，去掉端口、方括号和区域标识，
+// 如 [::]:22、127.0.0.53%lo:53、[fe80::1]%eth0:546；lsof 和 ss 的 * 返回 wildcard
+func localHost(addr string) (ip net.IP, wildcard bool) {
+	host := addr
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	host, _, _ = strings.Cut(host, "%")
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "*" {
+		return nil, true
+	}
+	return net.ParseIP(host), false
+}
+
+// addrFamily 返回本地地址的地址族 "4" 或 "6"，无法区分（如 *:22）时为空
+func addrFamily(addr string) string {
+	ip, _ := localHost(addr)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil && strings.Count(addr, ":") == 1:
+		return "4"
+	}
+	return "6"
+}
+
+// hostMatches 报告 IP 是否为本地地址的主机，* 与任一地址族的未指定地址相同
+func hostMatches(ip net.IP, addr string) bool {
+	host, wildcard := localHost(addr)
+	if wildcard {
+		return ip.IsUnspecified()
+	}
+	return host != nil && host.Equal(ip)
+}
+
+// sameHost 报告两个本地地址的主机是否相同，* 与任一地址族的未指定地址相同
+func sameHost(a, b string) bool {
+	ipA, wildcardA := localHost(a)
+	ipB, wildcardB := localHost(b)
+	switch {
+	case wildcardA && wildcardB:
+		return true
+	case wildcardA:
+		return ipB != nil && ipB.IsUnspecified()
+	case wildcardB:
+		return ipA != nil && ipA.IsUnspecified()
+	}
+	return ipA != nil && ipA.Equal(ipB)
+}
+
+// listeningOwner 返回在本地地址的端口上监听的唯一进程
+func listeningOwner(entries []socketEntry, port int, localAddr string) (int, bool) {
+	pids := make(map[int]bool)
+	for _, entry := range entries {
+		if entry.listening() && entry.Port == port && sameHost(entry.LocalAddr, localAddr) {
+			for _, pid := range entry.PIDs {
+				pids[pid] = true
+			}
+		}
+	}
+	return singleOwner(pids)
+}
+
+// singleOwner 返回唯一的进程，没有或有多个进程时不猜测
+func singleOwner(pids map[int]bool) (int, bool) {
+	if len(pids) != 1 {
+		return 0, false
+	}
+	for pid := range pids {
+		return pid, true
+	}
+	return 0, false
+}
+
+// extractPort 从 "addr:port" 形式的地址中提取端口号
+func extractPort(addr string) (int, bool) {
+	idx := strings.LastIndex(addr, ":")
+	if idx < 0 {
+		return 0, false
+	}
+	port, err := strconv.Atoi(addr[idx+1:])
+	if err != nil {
+		return 0, false
+	}
+	return port, true
+}
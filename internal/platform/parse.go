@@ -0,0 +1,66 @@
+package platform
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// socketEntry is one socket parsed from the output of ss, netstat or lsof, before the
+// owning process names and paths are resolved
+type socketEntry struct {
+	Protocol   string `json:"protocol"`
+	Port       int    `json:"port"`
+	LocalAddr  string `json:"local_addr"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	State      string `json:"state,omitempty"`
+	// PIDs lists every process sharing the socket, empty if the tool could not tell
+	PIDs []int `json:"pids,omitempty"`
+	// Command is the process name or path printed by the tool, if any
+	Command string `json:"command,omitempty"`
+}
+
+// listening reports whether the socket accepts connections. UDP sockets are UNCONN in
+// ss and have no state in netstat and lsof, where a connected one has a peer address.
+func (e socketEntry) listening() bool {
+	if e.State == "" {
+		return e.RemoteAddr == "" || strings.HasSuffix(e.RemoteAddr, ":*")
+	}
+	return e.State == "UNCONN" || strings.Contains(e.State, "LISTEN")
+}
+
+// scanLines returns the lines of r with surrounding whitespace, including the \r of
+// Windows line endings, removed
+func scanLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+	return lines, scanner.Err()
+}
+
+// parseProcNetAddr parses a local address of /proc/net/tcp, udp, tcp6 or udp6, e.g.
+// 0100007F:1F90 for 127.0.0.1:8080; the address is stored as 32-bit words in host
+// byte order, which is little-endian on the platforms this runs on
+func parseProcNetAddr(addr string) (net.IP, int, bool) {
+	hexIP, hexPort, found := strings.Cut(addr, ":")
+	ip, err := hex.DecodeString(hexIP)
+	if !found || err != nil || len(ip) != net.IPv4len && len(ip) != net.IPv6len {
+		return nil, 0, false
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return nil, 0, false
+	}
+	return net.IP(ip), int(port), true
+}
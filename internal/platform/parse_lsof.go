@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseLsof parses the output of `lsof -i -P -n` or `lsof -i :port`:
+//
+//	COMMAND  PID USER FD  TYPE DEVICE SIZE/OFF NODE NAME
+//	nginx   1200 root 6u  IPv4  23456      0t0  TCP *:80 (LISTEN)
+//	curl    4242 dev  5u  IPv4  34567      0t0  TCP 10.0.0.5:50000->93.184.216.34:443 (ESTABLISHED)
+//
+// Connected sockets carry the peer after "->" and the state in parentheses.
+func parseLsof(r io.Reader) ([]socketEntry, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []socketEntry
+	for _, line := range lines {
+		if line == "" || strings.HasPrefix(line, "COMMAND") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 9 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		localAddr, remoteAddr, _ := strings.Cut(fields[8], "->")
+		port, ok := extractPort(localAddr)
+		if !ok {
+			continue
+		}
+
+		var state string
+		if len(fields) >= 10 {
+			state = strings.Trim(fields[9], "()")
+		}
+
+		entries = append(entries, socketEntry{
+			Protocol:   strings.ToUpper(fields[7]),
+			Port:       port,
+			LocalAddr:  localAddr,
+			RemoteAddr: remoteAddr,
+			State:      state,
+			PIDs:       []int{pid},
+			Command:    fields[0],
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,120 @@
+package platform
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// parseNetstat parses the output of `netstat -tunlp` or `netstat -tunap` on Linux:
+//
+//	Proto Recv-Q Send-Q Local Address  Foreign Address  State   PID/Program name
+//	tcp        0      0 0.0.0.0:22     0.0.0.0:*        LISTEN  1001/sshd
+//	udp        0      0 0.0.0.0:68     0.0.0.0:*                845/dhclient
+//
+// UDP sockets usually have no state, and the program column is "-" for processes
+// owned by other users.
+func parseNetstat(r io.Reader) ([]socketEntry, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []socketEntry
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !isNumber(fields[1]) {
+			// 跳过 "Active Internet connections" 和表头
+			continue
+		}
+
+		localAddr := fields[3]
+		port, ok := extractPort(localAddr)
+		if !ok {
+			continue
+		}
+
+		entry := socketEntry{
+			Protocol:   strings.ToUpper(fields[0]),
+			Port:       port,
+			LocalAddr:  localAddr,
+			RemoteAddr: fields[4],
+		}
+
+		// 程序列可能包含空格，如 "1200/nginx: master"
+		processIdx := 5
+		if !strings.Contains(fields[5], "/") && fields[5] != "-" {
+			entry.State = fields[5]
+			processIdx = 6
+		}
+
+		if processIdx < len(fields) {
+			processInfo := strings.Join(fields[processIdx:], " ")
+			if pidStr, name, found := strings.Cut(processInfo, "/"); found {
+				if pid, err := strconv.Atoi(pidStr); err == nil {
+					entry.PIDs = []int{pid}
+					entry.Command = name
+				}
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// parseNetstatANO parses the output of `netstat -ano` on Windows:
+//
+//	Proto  Local Address    Foreign Address  State       PID
+//	TCP    0.0.0.0:135      0.0.0.0:0        LISTENING   1000
+//	UDP    0.0.0.0:5353     *:*                          2400
+//
+// UDP sockets have no state. The headers are localized, so only TCP and UDP rows
+// are recognized.
+func parseNetstatANO(r io.Reader) ([]socketEntry, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []socketEntry
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "TCP") && !strings.HasPrefix(line, "UDP") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		var state, pidStr string
+		if len(fields) >= 5 {
+			state, pidStr = fields[3], fields[4]
+		} else {
+			pidStr = fields[3]
+		}
+
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil {
+			continue
+		}
+
+		port, ok := extractPort(fields[1])
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, socketEntry{
+			Protocol:   fields[0],
+			Port:       port,
+			LocalAddr:  fields[1],
+			RemoteAddr: fields[2],
+			State:      state,
+			PIDs:       []int{pid},
+		})
+	}
+
+	return entries, nil
+}
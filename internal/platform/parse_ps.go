@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/types"
+)
+
+// parsePS parses the output of `ps -axo pid,ppid,comm` on Linux and macOS. The
+// command may contain spaces, and is the full executable path on macOS.
+func parsePS(r io.Reader) ([]types.ProcessInfo, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []types.ProcessInfo
+	for i, line := range lines {
+		if i == 0 {
+			continue // Skip header
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+
+		processes = append(processes, types.ProcessInfo{
+			PID:  pid,
+			PPID: ppid,
+			Name: strings.Join(fields[2:], " "),
+		})
+	}
+
+	return processes, nil
+}
+
+// parsePSNames parses the output of `ps -axo pid,comm` into a PID to command map
+func parsePSNames(r io.Reader) (map[int]string, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	for i, line := range lines {
+		if i == 0 {
+			continue // Skip header
+		}
+
+		pidStr, name, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		if pid, err := strconv.Atoi(pidStr); err == nil {
+			names[pid] = strings.TrimSpace(name)
+		}
+	}
+
+	return names, nil
+}
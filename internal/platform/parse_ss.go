@@ -0,0 +1,94 @@
+package platform
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ssPIDRegexp 匹配 ss 进程列中的 pid 字段，一个套接字可能被多个进程共享
+var ssPIDRegexp = regexp.MustCompile(`pid=(\d+)`)
+
+// ssCommandRegexp 匹配 ss 进程列中第一个进程名，如 users:(("nginx",pid=1200,fd=7))
+var ssCommandRegexp = regexp.MustCompile(`\("([^"]*)",pid=`)
+
+// parseSS parses the output of `ss -tunlp` or `ss -tunap`:
+//
+//	Netid State  Recv-Q Send-Q Local Address:Port Peer Address:Port Process
+//	tcp   LISTEN 0      511    0.0.0.0:80         0.0.0.0:*         users:(("nginx",pid=1200,fd=7))
+//
+// The process column is missing when ss runs without privileges, and some WSL builds
+// of ss also drop the Recv-Q and Send-Q columns.
+func parseSS(r io.Reader) ([]socketEntry, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []socketEntry
+	for i, line := range lines {
+		if i == 0 || line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+
+		// 处理 WSL 中缺少 Recv-Q/Send-Q 列的输出
+		localIdx := 4
+		if len(fields) <= 4 || !isNumber(fields[2]) {
+			localIdx = 2
+		}
+		if localIdx >= len(fields) {
+			continue
+		}
+
+		localAddr := fields[localIdx]
+		port, ok := extractPort(localAddr)
+		if !ok {
+			continue
+		}
+
+		entry := socketEntry{
+			Protocol:  strings.ToUpper(fields[0]),
+			Port:      port,
+			LocalAddr: localAddr,
+			State:     fields[1],
+		}
+		if localIdx+1 < len(fields) && !strings.HasPrefix(fields[localIdx+1], "users:") {
+			entry.RemoteAddr = fields[localIdx+1]
+		}
+
+		seen := make(map[int]bool)
+		for _, match := range ssPIDRegexp.FindAllStringSubmatch(line, -1) {
+			pid, _ := strconv.Atoi(match[1])
+			if !seen[pid] {
+				seen[pid] = true
+				entry.PIDs = append(entry.PIDs, pid)
+			}
+		}
+		if match := ssCommandRegexp.FindStringSubmatch(line); match != nil {
+			entry.Command = match[1]
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// isNumber reports whether s consists of decimal digits only
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package platform
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"portreleasor/internal/types"
+)
+
+// parseTasklist parses the output of `tasklist /FO CSV /NH` into a PID to image name map:
+//
+//	"System Idle Process","0","Services","0","8 K"
+//	"svchost.exe","1000","Services","0","12,345 K"
+func parseTasklist(r io.Reader) (map[int]string, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	for _, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		if pid, err := strconv.Atoi(record[1]); err == nil {
+			names[pid] = record[0]
+		}
+	}
+
+	return names, nil
+}
+
+// parseTasklistUser parses the user name from `tasklist /FI "PID eq n" /V /FO CSV /NH`,
+// whose seventh column is the user: "Image","PID","Session","Session#","Mem","Status","User",...
+func parseTasklistUser(r io.Reader) (string, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return "", err
+	}
+
+	for _, record := range records {
+		if len(record) < 7 {
+			continue
+		}
+		if name := record[6]; name != "" && name != "N/A" {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("process user not found")
+}
+
+// parseWmicPaths parses the output of `wmic process get ProcessId,ExecutablePath /format:csv`
+// into a PID to executable path map. wmic orders the columns alphabetically after
+// Node: Node,ExecutablePath,ProcessId.
+func parseWmicPaths(r io.Reader) (map[int]string, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[int]string)
+	for _, record := range records {
+		if len(record) < 3 || record[0] == "Node" {
+			continue
+		}
+
+		pid, err := strconv.Atoi(record[len(record)-1])
+		if err != nil {
+			continue
+		}
+		// 路径本身可能包含逗号
+		if path := strings.Join(record[1:len(record)-1], ","); path != "" {
+			paths[pid] = path
+		}
+	}
+
+	return paths, nil
+}
+
+// parseWmicProcesses parses the output of `wmic process get Name,ParentProcessId,ProcessId /format:csv`,
+// whose columns are Node,Name,ParentProcessId,ProcessId
+func parseWmicProcesses(r io.Reader) ([]types.ProcessInfo, error) {
+	records, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []types.ProcessInfo
+	for _, record := range records {
+		if len(record) < 4 || record[0] == "Node" {
+			continue
+		}
+
+		pid, err := strconv.Atoi(record[len(record)-1])
+		if err != nil {
+			continue
+		}
+		ppid, err := strconv.Atoi(record[len(record)-2])
+		if err != nil {
+			continue
+		}
+
+		processes = append(processes, types.ProcessInfo{
+			PID:  pid,
+			PPID: ppid,
+			Name: strings.Join(record[1:len(record)-2], ","),
+		})
+	}
+
+	return processes, nil
+}
+
+// parseWmicValue returns the value of key from `wmic ... get <key> /format:list` output,
+// which prints one "Key=Value" line per property
+func parseWmicValue(r io.Reader, key string) (string, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range lines {
+		if value, found := strings.CutPrefix(line, key+"="); found {
+			if value = strings.TrimSpace(value); value != "" {
+				return value, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%s not found", key)
+}
+
+// parseCIMDateTime parses a CIM_DATETIME value such as 20240102150405.123456+480,
+// where the suffix is the offset from UTC in minutes
+func parseCIMDateTime(value string) (time.Time, error) {
+	if len(value) < 25 {
+		return time.Time{}, fmt.Errorf("invalid CIM datetime %q", value)
+	}
+	offset, err := strconv.Atoi(value[21:])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid CIM datetime %q: %v", value, err)
+	}
+	zone := time.FixedZone("", offset*60)
+	return time.ParseInLocation("20060102150405.000000", value[:21], zone)
+}
+
+// readCSV reads the CSV records printed by tasklist and wmic, skipping blank lines
+// and tolerating the "\r\r\n" line endings wmic writes to pipes
+func readCSV(r io.Reader) ([][]string, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		record, err := reader.Read()
+		if err != nil {
+			continue
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,258 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files in testdata")
+
+// parserCase parses one captured tool output in testdata
+type parserCase struct {
+	fixture string
+	parse   func(r io.Reader) (interface{}, error)
+}
+
+func sockets(parse func(io.Reader) ([]socketEntry, error)) func(io.Reader) (interface{}, error) {
+	return func(r io.Reader) (interface{}, error) { return parse(r) }
+}
+
+func wmicValue(key string) func(io.Reader) (interface{}, error) {
+	return func(r io.Reader) (interface{}, error) { return parseWmicValue(r, key) }
+}
+
+var parserCases = []parserCase{
+	// Linux
+	{"ss_tunap.txt", sockets(parseSS)},
+	{"ss_tunlp.txt", sockets(parseSS)},
+	{"ss_nonroot.txt", sockets(parseSS)},
+	{"ss_short_columns.txt", sockets(parseSS)},
+	{"netstat_tunap.txt", sockets(parseNetstat)},
+	{"netstat_tunlp.txt", sockets(parseNetstat)},
+	{"lsof_linux_port.txt", sockets(parseLsof)},
+	{"ps_linux.txt", func(r io.Reader) (interface{}, error) { return parsePS(r) }},
+	// macOS
+	{"lsof_darwin.txt", sockets(parseLsof)},
+	{"ps_darwin.txt", func(r io.Reader) (interface{}, error) { return parsePS(r) }},
+	{"ps_names_darwin.txt", func(r io.Reader) (interface{}, error) { return parsePSNames(r) }},
+	// Windows
+	{"netstat_ano.txt", sockets(parseNetstatANO)},
+	{"netstat_ano_zh.txt", sockets(parseNetstatANO)},
+	{"tasklist.txt", func(r io.Reader) (interface{}, error) { return parseTasklist(r) }},
+	{"tasklist_verbose.txt", func(r io.Reader) (interface{}, error) { return parseTasklistUser(r) }},
+	{"wmic_paths.txt", func(r io.Reader) (interface{}, error) { return parseWmicPaths(r) }},
+	{"wmic_processes.txt", func(r io.Reader) (interface{}, error) { return parseWmicProcesses(r) }},
+	{"wmic_commandline.txt", wmicValue("CommandLine")},
+	{"wmic_creationdate.txt", func(r io.Reader) (interface{}, error) {
+		value, err := parseWmicValue(r, "CreationDate")
+		if err != nil {
+			return nil, err
+		}
+		return parseCIMDateTime(value)
+	}},
+}
+
+func TestParsersGolden(t *testing.T) {
+	for _, tc := range parserCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			input, err := os.Open(filepath.Join("testdata", tc.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer input.Close()
+
+			result, err := tc.parse(input)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			got, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", strings.TrimSuffix(tc.fixture, ".txt")+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestParseTasklistUserNotAvailable(t *testing.T) {
+	input, err := os.Open(filepath.Join("testdata", "tasklist_verbose_system.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+
+	if user, err := parseTasklistUser(input); err == nil {
+		t.Errorf("parseTasklistUser() = %q, want an error for N/A", user)
+	}
+}
+
+func TestParseSSSharedSocket(t *testing.T) {
+	const output = "Netid State Recv-Q Send-Q Local Address:Port Peer Address:Port Process\n" +
+		`tcp LISTEN 0 511 0.0.0.0:80 0.0.0.0:* users:(("nginx",pid=1201,fd=6),("nginx",pid=1200,fd=6),("nginx",pid=1201,fd=7))` + "\n"
+
+	entries, err := parseSS(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if got := entries[0].PIDs; len(got) != 2 || got[0] != 1201 || got[1] != 1200 {
+		t.Errorf("PIDs = %v, want [1201 1200] without duplicates", got)
+	}
+}
+
+func TestExtractPort(t *testing.T) {
+	tests := []struct {
+		addr string
+		port int
+		ok   bool
+	}{
+		{"0.0.0.0:22", 22, true},
+		{"[::]:443", 443, true},
+		{"127.0.0.53%lo:53", 53, true},
+		{"[fe80::1%12]:1900", 1900, true},
+		{"*:*", 0, false},
+		{"localhost", 0, false},
+	}
+
+	for _, tt := range tests {
+		port, ok := extractPort(tt.addr)
+		if port != tt.port || ok != tt.ok {
+			t.Errorf("extractPort(%q) = %d, %v, want %d, %v", tt.addr, port, ok, tt.port, tt.ok)
+		}
+	}
+}
+
+func TestLocalHost(t *testing.T) {
+	tests := []struct {
+		addr     string
+		ip       string
+		wildcard bool
+		family   string
+	}{
+		{"0.0.0.0:22", "0.0.0.0", false, "4"},
+		{"127.0.0.53%lo:53", "127.0.0.53", false, "4"},
+		{"[::]:22", "::", false, "6"},
+		{":::22", "::", false, "6"},
+		{"[::ffff:127.0.0.1]:8080", "127.0.0.1", false, "6"},
+		{"[fe80::1]%eth0:546", "fe80::1", false, "6"},
+		{"[fe80::1%eth0]:546", "fe80::1", false, "6"},
+		{"*:22", "", true, ""},
+		{"localhost:22", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		ip, wildcard := localHost(tt.addr)
+		if got := fmt.Sprint(ip); tt.ip != "" && got != tt.ip || tt.ip == "" && ip != nil || wildcard != tt.wildcard {
+			t.Errorf("localHost(%q) = %v, %v, want %s, %v", tt.addr, ip, wildcard, tt.ip, tt.wildcard)
+		}
+		if family := addrFamily(tt.addr); family != tt.family {
+			t.Errorf("addrFamily(%q) = %q, want %q", tt.addr, family, tt.family)
+		}
+	}
+}
+
+func TestSameHost(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"127.0.0.1:8080", "127.0.0.1:8080", true},
+		{"127.0.0.1:8080", "0.0.0.0:8080", false},
+		{"*:8080", "0.0.0.0:8080", true},
+		{"*:8080", "[::]:8080", true},
+		{"*:8080", "127.0.0.1:8080", false},
+		{"*:8080", "*:8080", true},
+		{"[::1]:8080", "::1:8080", true},
+		{"[::1]:8080", "127.0.0.1:8080", false},
+		{"127.0.0.53:53", "127.0.0.53%lo:53", true},
+		{"localhost:8080", "localhost:8080", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameHost(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameHost(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestListeningOwner(t *testing.T) {
+	entries := []socketEntry{
+		{Protocol: "TCP", Port: 8080, LocalAddr: "*:8080", State: "LISTEN", PIDs: []int{4242}},
+		{Protocol: "TCP", Port: 8080, LocalAddr: "[::1]:8080", State: "LISTEN", PIDs: []int{5000}},
+		{Protocol: "TCP", Port: 8080, LocalAddr: "127.0.0.1:8080", RemoteAddr: "127.0.0.1:51300", State: "ESTABLISHED", PIDs: []int{6000}},
+		{Protocol: "TCP", Port: 9000, LocalAddr: "127.0.0.1:9000", State: "LISTEN", PIDs: []int{7001, 7002}},
+		{Protocol: "TCP", Port: 9000, LocalAddr: "127.0.0.1:9000", State: "LISTEN", PIDs: []int{7003}},
+	}
+
+	tests := []struct {
+		port      int
+		localAddr string
+		pid       int
+		ok        bool
+	}{
+		{8080, "0.0.0.0:8080", 4242, true},
+		{8080, "[::1]:8080", 5000, true},
+		// 其他地址上的监听者不能当作所有者
+		{8080, "127.0.0.1:8080", 0, false},
+		// 共享端口的多个进程无法确定所有者
+		{9000, "127.0.0.1:9000", 0, false},
+		{9001, "127.0.0.1:9001", 0, false},
+	}
+
+	for _, tt := range tests {
+		pid, ok := listeningOwner(entries, tt.port, tt.localAddr)
+		if pid != tt.pid || ok != tt.ok {
+			t.Errorf("listeningOwner(%d, %q) = %d, %v, want %d, %v", tt.port, tt.localAddr, pid, ok, tt.pid, tt.ok)
+		}
+	}
+}
+
+func TestParseProcNetAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		ip   string
+		port int
+		ok   bool
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080, true},
+		{"00000000:0016", "0.0.0.0", 22, true},
+		{"00000000000000000000000001000000:1F90", "::1", 8080, true},
+		{"0000000000000000FFFF00000100007F:0050", "127.0.0.1", 80, true},
+		{"00000000000000000000000000000000:0016", "::", 22, true},
+		{"0100007F", "", 0, false},
+		{"0100:0016", "", 0, false},
+		{"0100007F:XYZ", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		ip, port, ok := parseProcNetAddr(tt.addr)
+		if ok != tt.ok || ok && (ip.String() != tt.ip || port != tt.port) {
+			t.Errorf("parseProcNetAddr(%q) = %v, %d, %v, want %s, %d, %v", tt.addr, ip, port, ok, tt.ip, tt.port, tt.ok)
+		}
+	}
+}
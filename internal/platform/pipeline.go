@@ -0,0 +1,298 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"portreleasor/internal/i18n"
+	"portreleasor/internal/types"
+	"portreleasor/internal/utils"
+)
+
+// socketCollector lists the sockets of the system from one data source, e.g. ss
+type socketCollector struct {
+	name string
+	// collect returns the sockets, or at least the listening ones when listening is set
+	collect func(ctx context.Context, listening bool) ([]socketEntry, error)
+}
+
+// pidResolver finds the owner of a listening socket its collector could not attribute,
+// identified by its port, protocol and local address. It must not guess: ok is false
+// when no process or more than one process holds a socket at that address.
+type pidResolver func(ctx context.Context, port int, protocol, localAddr string) (pid int, ok bool)
+
+// processDetails are the process fields filled in by enrichers, empty when unknown
+type processDetails struct {
+	Name string
+	Path string
+}
+
+// processEnricher fills in the details of the given processes. Fields set by an earlier
+// enricher must be kept, so enrichers are listed in order of preference.
+type processEnricher func(ctx context.Context, details map[int]*processDetails) error
+
+// pipeline discovers sockets and their owners in stages shared by all platforms;
+// platform code only supplies the stages:
+//
+//  1. collectors are tried in order until one succeeds
+//  2. for listening queries, sockets are deduplicated by port and protocol
+//  3. resolvers attribute listening sockets the collector reported without owner
+//  4. enrichers resolve the name and path of every owning process
+//
+// Steps 3 and 4 are deferred by sockets and resolve, so callers can filter first and
+// only pay for the processes they show. Both record the status of the pid, name and
+// path fields on each socket they attribute.
+type pipeline struct {
+	collectors []socketCollector
+	resolvers  []pidResolver
+	enrichers  []processEnricher
+	// unowned is the status of listening sockets no resolver could attribute, empty
+	// when those are kernel sockets without owner rather than hidden ones
+	unowned types.FieldStatus
+	// status explains a name or path the enrichers could not resolve, unsupported if nil
+	status func(ctx context.Context, pid int) types.FieldStatus
+}
+
+// listening returns one listening socket per port and protocol with its owner resolved
+func (p *pipeline) listening(ctx context.Context) ([]types.PortInfo, error) {
+	sockets, err := p.sockets(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	p.resolve(ctx, sockets)
+	return sockets, ctx.Err()
+}
+
+// all returns every socket, listening and connected, one entry per owning process
+func (p *pipeline) all(ctx context.Context) ([]types.PortInfo, error) {
+	sockets, err := p.sockets(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	p.resolve(ctx, sockets)
+	return sockets, ctx.Err()
+}
+
+// sockets collects the sockets without resolving their owners further; ProcessName
+// is the name printed by the collector, if any
+func (p *pipeline) sockets(ctx context.Context, listening bool) ([]types.PortInfo, error) {
+	entries, err := p.collect(ctx, listening)
+	if err != nil {
+		return nil, err
+	}
+
+	var sockets []types.PortInfo
+	for _, entry := range entries {
+		if listening && !entry.listening() {
+			continue
+		}
+
+		info := types.PortInfo{
+			Port:       entry.Port,
+			Protocol:   entry.Protocol,
+			LocalAddr:  entry.LocalAddr,
+			RemoteAddr: entry.RemoteAddr,
+			State:      entry.State,
+		}
+		if listening {
+			info.RemoteAddr = ""
+			info.State = "LISTENING"
+		}
+
+		if len(entry.PIDs) == 0 {
+			sockets = append(sockets, info)
+			continue
+		}
+
+		// 一个套接字可能被多个进程共享（如 nginx master 和 worker）
+		for _, pid := range entry.PIDs {
+			owned := info
+			owned.PID = pid
+			owned.ProcessName = entry.Command
+			sockets = append(sockets, owned)
+		}
+	}
+
+	if listening {
+		return uniquePorts(sockets), nil
+	}
+	return sockets, nil
+}
+
+// resolve finds the owners of unattributed listening sockets concurrently, then fills
+// in the name and path of every owning process. Fields stay unresolved once ctx is done.
+func (p *pipeline) resolve(ctx context.Context, sockets []types.PortInfo) {
+	if len(p.resolvers) > 0 {
+		var unowned []int
+		for i, socket := range sockets {
+			if socket.PID == 0 && (socketEntry{State: socket.State, RemoteAddr: socket.RemoteAddr}).listening() {
+				unowned = append(unowned, i)
+			}
+		}
+
+		utils.ForEach(len(unowned), func(i int) {
+			socket := &sockets[unowned[i]]
+			for _, resolve := range p.resolvers {
+				if ctx.Err() != nil {
+					return
+				}
+				if pid, ok := resolve(ctx, socket.Port, socket.Protocol, socket.LocalAddr); ok {
+					socket.PID = pid
+					return
+				}
+			}
+		})
+	}
+
+	for i := range sockets {
+		socket := &sockets[i]
+		if socket.PID > 0 {
+			socket.SetStatus(types.FieldPID, types.StatusOK)
+		} else if p.unowned != "" && (socketEntry{State: socket.State, RemoteAddr: socket.RemoteAddr}).listening() {
+			socket.SetStatus(types.FieldPID, p.unowned)
+			socket.SetStatus(types.FieldProcessName, p.unowned)
+			socket.SetStatus(types.FieldProcessPath, p.unowned)
+		}
+	}
+
+	details := make(map[int]*processDetails)
+	for _, socket := range sockets {
+		if socket.PID > 0 {
+			details[socket.PID] = &processDetails{}
+		}
+	}
+	if len(details) == 0 {
+		return
+	}
+
+	// 补全失败的字段保持为空，由后续的补全器或采集工具输出的进程名兜底
+	for _, enrich := range p.enrichers {
+		if ctx.Err() != nil {
+			return
+		}
+		_ = enrich(ctx, details)
+	}
+
+	statuses := make(map[int]types.FieldStatus)
+	statusOf := func(pid int) types.FieldStatus {
+		status, checked := statuses[pid]
+		if !checked {
+			status = types.StatusUnsupported
+			if p.status != nil {
+				status = p.status(ctx, pid)
+			}
+			statuses[pid] = status
+		}
+		return status
+	}
+
+	for i := range sockets {
+		socket := &sockets[i]
+		d := details[socket.PID]
+		if d == nil {
+			continue
+		}
+		if d.Name != "" {
+			socket.ProcessName = d.Name
+		}
+		if d.Path != "" {
+			socket.ProcessPath = d.Path
+		}
+
+		if socket.ProcessName != "" {
+			socket.SetStatus(types.FieldProcessName, types.StatusOK)
+		} else {
+			socket.SetStatus(types.FieldProcessName, statusOf(socket.PID))
+		}
+		if socket.ProcessPath != "" {
+			socket.SetStatus(types.FieldProcessPath, types.StatusOK)
+		} else {
+			socket.SetStatus(types.FieldProcessPath, statusOf(socket.PID))
+		}
+	}
+}
+
+// collect returns the sockets of the first collector that succeeds, reporting the
+// fallback when an earlier one failed
+func (p *pipeline) collect(ctx context.Context, listening bool) ([]socketEntry, error) {
+	var failures collectFailures
+	var fallback string
+	for _, collector := range p.collectors {
+		entries, err := collector.collect(ctx, listening)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil {
+			if fallback != "" {
+				reportf(ctx, "%s; using %s instead", fallback, collector.name)
+			}
+			return entries, nil
+		}
+
+		failures = append(failures, i18n.Errorf("%s failed: %w", collector.name, err))
+		// 超时已由 runCommand 报告，不再重复原因
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fallback = i18n.Sprintf("%s timed out", collector.name)
+		} else {
+			fallback = i18n.Localize(failures[len(failures)-1])
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil, i18n.Errorf("no socket collector available")
+	}
+	return nil, failures
+}
+
+// collectFailures are the errors of every collector when none succeeded; errors.As
+// finds a *TimeoutError among them
+type collectFailures []error
+
+func (f collectFailures) Error() string {
+	messages := make([]string, len(f))
+	for i, err := range f {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("failed to list sockets (%s)", strings.Join(messages, "; "))
+}
+
+// Localize returns the message in the selected language
+func (f collectFailures) Localize() string {
+	messages := make([]string, len(f))
+	for i, err := range f {
+		messages[i] = i18n.Localize(err)
+	}
+	return i18n.Sprintf("failed to list sockets (%s)", strings.Join(messages, "; "))
+}
+
+func (f collectFailures) Unwrap() []error {
+	return f
+}
+
+// toolCollector collects sockets by running a command and parsing its output
+func toolCollector(name string, args func(listening bool) []string, parse func(io.Reader) ([]socketEntry, error)) socketCollector {
+	return socketCollector{
+		name: name,
+		collect: func(ctx context.Context, listening bool) ([]socketEntry, error) {
+			out, err := runCommand(ctx, name, args(listening)...)
+			if err != nil {
+				return nil, err
+			}
+
+			entries, err := parse(out)
+			if err != nil {
+				return nil, i18n.Errorf("failed to parse %s output: %w", name, err)
+			}
+			return entries, nil
+		},
+	}
+}
+
+// fixedArgs returns the same arguments for listening and full queries
+func fixedArgs(args ...string) func(bool) []string {
+	return func(bool) []string { return args }
+}
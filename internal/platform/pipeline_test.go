@@ -0,0 +1,201 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"portreleasor/internal/types"
+)
+
+// stubCollector returns the given sockets, or fails when entries is nil
+func stubCollector(name string, entries []socketEntry) socketCollector {
+	return socketCollector{
+		name: name,
+		collect: func(context.Context, bool) ([]socketEntry, error) {
+			if entries == nil {
+				return nil, errors.New("not installed")
+			}
+			return append([]socketEntry(nil), entries...), nil
+		},
+	}
+}
+
+var pipelineEntries = []socketEntry{
+	{Protocol: "TCP", Port: 80, LocalAddr: "0.0.0.0:80", RemoteAddr: "0.0.0.0:*", State: "LISTEN", PIDs: []int{1201, 1200}, Command: "nginx"},
+	{Protocol: "TCP", Port: 80, LocalAddr: "10.0.0.5:80", RemoteAddr: "10.0.0.9:51300", State: "ESTAB", PIDs: []int{1201}, Command: "nginx"},
+	{Protocol: "TCP", Port: 2049, LocalAddr: "0.0.0.0:2049", RemoteAddr: "0.0.0.0:*", State: "LISTEN"},
+	{Protocol: "UDP", Port: 5353, LocalAddr: "*:5353", PIDs: []int{312}, Command: "mDNSRespo"},
+	{Protocol: "UDP", Port: 5353, LocalAddr: "10.0.0.5:5353", RemoteAddr: "10.0.0.9:5353", PIDs: []int{312}, Command: "mDNSRespo"},
+}
+
+func TestPipelineListening(t *testing.T) {
+	p := &pipeline{
+		collectors: []socketCollector{stubCollector("ss", nil), stubCollector("netstat", pipelineEntries)},
+		resolvers: []pidResolver{
+			func(ctx context.Context, port int, protocol, localAddr string) (int, bool) { return 0, false },
+			func(ctx context.Context, port int, protocol, localAddr string) (int, bool) { return 900, port == 2049 },
+		},
+		enrichers: []processEnricher{
+			func(ctx context.Context, details map[int]*processDetails) error {
+				details[312].Name = "mDNSResponder"
+				return errors.New("partial failure")
+			},
+			func(ctx context.Context, details map[int]*processDetails) error {
+				for pid, d := range details {
+					if d.Name == "" {
+						d.Name = map[int]string{1201: "nginx", 312: "ignored"}[pid]
+					}
+					d.Path = map[int]string{312: "/usr/sbin/mDNSResponder"}[pid]
+				}
+				return nil
+			},
+		},
+		status: func(ctx context.Context, pid int) types.FieldStatus {
+			if pid == 900 {
+				return types.StatusProcessGone
+			}
+			return types.StatusPermissionDenied
+		},
+	}
+
+	got, err := p.listening(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.PortInfo{
+		{Port: 80, Protocol: "TCP", PID: 1201, ProcessName: "nginx", LocalAddr: "0.0.0.0:80", State: "LISTENING",
+			Status: map[string]types.FieldStatus{"pid": "ok", "process_name": "ok", "process_path": "permission-denied"}},
+		{Port: 2049, Protocol: "TCP", PID: 900, LocalAddr: "0.0.0.0:2049", State: "LISTENING",
+			Status: map[string]types.FieldStatus{"pid": "ok", "process_name": "process-gone", "process_path": "process-gone"}},
+		{Port: 5353, Protocol: "UDP", PID: 312, ProcessName: "mDNSResponder", ProcessPath: "/usr/sbin/mDNSResponder", LocalAddr: "*:5353", State: "LISTENING",
+			Status: map[string]types.FieldStatus{"pid": "ok", "process_name": "ok", "process_path": "ok"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listening() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestPipelineUnowned(t *testing.T) {
+	p := &pipeline{
+		collectors: []socketCollector{stubCollector("ss", pipelineEntries)},
+		unowned:    types.StatusPermissionDenied,
+	}
+
+	got, err := p.listening(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 无法识别所有者的监听端口标记为无权限，而不是伪造进程名
+	hidden := got[1]
+	want := map[string]types.FieldStatus{"pid": "permission-denied", "process_name": "permission-denied", "process_path": "permission-denied"}
+	if hidden.Port != 2049 || hidden.PID != 0 || hidden.ProcessName != "" || !reflect.DeepEqual(hidden.Status, want) {
+		t.Errorf("unowned socket = %v with status %v, want no owner and status %v", hidden, hidden.Status, want)
+	}
+}
+
+func TestPipelineAll(t *testing.T) {
+	p := &pipeline{collectors: []socketCollector{stubCollector("lsof", pipelineEntries)}}
+
+	got, err := p.all(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 共享套接字每个进程一条记录，未补全的进程名使用采集工具输出的名称
+	var pids []int
+	for _, socket := range got {
+		pids = append(pids, socket.PID)
+		if socket.PID != 0 && socket.ProcessName == "" {
+			t.Errorf("%v: process name not taken from the collector", socket)
+		}
+	}
+	if want := []int{1201, 1200, 1201, 0, 312, 312}; !reflect.DeepEqual(pids, want) {
+		t.Errorf("PIDs = %v, want %v", pids, want)
+	}
+	if got[1].State != "LISTEN" || got[1].RemoteAddr != "0.0.0.0:*" {
+		t.Errorf("all() rewrote the socket state: %v", got[1])
+	}
+}
+
+func TestPipelineLazyResolve(t *testing.T) {
+	var resolved, enriched []int
+	p := &pipeline{
+		collectors: []socketCollector{stubCollector("ss", pipelineEntries)},
+		resolvers: []pidResolver{func(ctx context.Context, port int, protocol, localAddr string) (int, bool) {
+			resolved = append(resolved, port)
+			return 900, true
+		}},
+		enrichers: []processEnricher{func(ctx context.Context, details map[int]*processDetails) error {
+			for pid := range details {
+				enriched = append(enriched, pid)
+			}
+			return nil
+		}},
+	}
+
+	sockets, err := p.sockets(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 0 || len(enriched) != 0 {
+		t.Fatalf("sockets() resolved %v and enriched %v, want nothing", resolved, enriched)
+	}
+
+	// 只解析筛选后的端口
+	var matched []types.PortInfo
+	for _, socket := range sockets {
+		if socket.Port == 80 {
+			matched = append(matched, socket)
+		}
+	}
+	p.resolve(context.Background(), matched)
+
+	if len(resolved) != 0 || !reflect.DeepEqual(enriched, []int{1201}) {
+		t.Errorf("resolve() resolved %v and enriched %v, want nothing and [1201]", resolved, enriched)
+	}
+}
+
+func TestPipelineNoCollector(t *testing.T) {
+	p := &pipeline{collectors: []socketCollector{stubCollector("ss", nil), stubCollector("netstat", nil)}}
+
+	_, err := p.listening(context.Background())
+	if err == nil || err.Error() != "failed to list sockets (ss failed: not installed; netstat failed: not installed)" {
+		t.Errorf("listening() error = %v", err)
+	}
+}
+
+func TestPipelineCollectorTimeout(t *testing.T) {
+	timedOut := socketCollector{
+		name: "ss",
+		collect: func(context.Context, bool) ([]socketEntry, error) {
+			return nil, &TimeoutError{Backend: "ss", Timeout: time.Second}
+		},
+	}
+	p := &pipeline{collectors: []socketCollector{timedOut, stubCollector("netstat", nil)}}
+
+	_, err := p.listening(context.Background())
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Backend != "ss" {
+		t.Errorf("listening() error = %v, want it to wrap the timeout of ss", err)
+	}
+}
+
+func TestPipelineReportsFallback(t *testing.T) {
+	var reports []string
+	ctx := WithReporter(context.Background(), func(message string) { reports = append(reports, message) })
+
+	p := &pipeline{collectors: []socketCollector{stubCollector("ss", nil), stubCollector("netstat", pipelineEntries)}}
+	if _, err := p.listening(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"ss failed: not installed; using netstat instead"}
+	if !reflect.DeepEqual(reports, want) {
+		t.Errorf("reports = %q, want %q", reports, want)
+	}
+}
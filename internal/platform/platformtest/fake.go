@@ -0,0 +1,211 @@
+// Package platformtest provides a fake platform manager for tests. It is imported only
+// by test files, so it is not part of the binary.
+package platformtest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+)
+
+// FakeManager is an in-memory PlatformManager that serves captured tool output, so
+// the core commands can be tested on any OS against every platform's format. Killed
+// processes disappear from the sockets and process list it returns.
+type FakeManager struct {
+	// Replay holds the captured socket listing: its Format, Output, Delay and whether
+	// it was captured Unprivileged
+	Replay
+	// Processes is the process table used to resolve names and parents
+	Processes []types.ProcessInfo
+	// Paths maps PIDs to executable paths
+	Paths map[int]string
+	// Users maps PIDs to user names
+	Users map[int]string
+	// KillErrors makes KillProcessByPID fail for the given PIDs
+	KillErrors map[int]error
+	// Denied marks PIDs whose details the caller may not read; lookups of their path,
+	// user and other details fail with permission denied
+	Denied map[int]bool
+
+	mu     sync.Mutex
+	killed map[int]bool
+}
+
+// Install makes GetPlatformManager return the fake and returns a function restoring
+// the previous manager
+func (f *FakeManager) Install() (restore func()) {
+	previous := platform.GetPlatformManager
+	platform.GetPlatformManager = func() platform.PlatformManager { return f }
+	return func() { platform.GetPlatformManager = previous }
+}
+
+// Killed returns the PIDs killed so far, in process table order
+func (f *FakeManager) Killed() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var pids []int
+	for _, proc := range f.Processes {
+		if f.killed[proc.PID] {
+			pids = append(pids, proc.PID)
+		}
+	}
+	return pids
+}
+
+// Sockets serves Output without the killed processes through the pipeline of the
+// platform managers, resolving names from Processes and paths from Paths
+func (f *FakeManager) Sockets() *platform.Sockets {
+	return platform.NewSockets(platform.SocketSource{
+		Format:  f.Format,
+		Output:  f.Replay.output,
+		Alive:   f.alive,
+		Process: f.details,
+		Status:  f.ProcessStatus,
+		Unowned: f.Replay.unowned(),
+	})
+}
+
+// GetPortConnections returns one listening socket per port and protocol
+func (f *FakeManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return f.Sockets().GetPortConnections(ctx)
+}
+
+// GetListeningSockets returns one listening socket per port and protocol, named after
+// the process the captured output shows
+func (f *FakeManager) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return f.Sockets().GetListeningSockets(ctx)
+}
+
+// ResolveProcesses fills in process names from Processes and paths from Paths
+func (f *FakeManager) ResolveProcesses(ctx context.Context, sockets []types.PortInfo) {
+	f.Sockets().ResolveProcesses(ctx, sockets)
+}
+
+// GetAllSockets returns every socket, one entry per owning process
+func (f *FakeManager) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return f.Sockets().GetAllSockets(ctx)
+}
+
+// KillProcessByPID marks the process as killed
+func (f *FakeManager) KillProcessByPID(ctx context.Context, pid int) error {
+	if err := f.KillErrors[pid]; err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.killed == nil {
+		f.killed = make(map[int]bool)
+	}
+	if f.killed[pid] || f.process(pid) == nil {
+		return fmt.Errorf("failed to kill process %d: no such process", pid)
+	}
+	f.killed[pid] = true
+	return nil
+}
+
+// GetProcessPath returns the path from Paths
+func (f *FakeManager) GetProcessPath(ctx context.Context, pid int) (string, error) {
+	if f.Denied[pid] {
+		return "", fmt.Errorf("failed to get process path: permission denied")
+	}
+	if path := f.Paths[pid]; path != "" {
+		return path, nil
+	}
+	return "", fmt.Errorf("process path not found")
+}
+
+// GetProcessCommandLine is not recorded by the fake
+func (f *FakeManager) GetProcessCommandLine(ctx context.Context, pid int) (string, error) {
+	return "", fmt.Errorf("process command line not found")
+}
+
+// GetProcessCwd is not recorded by the fake
+func (f *FakeManager) GetProcessCwd(ctx context.Context, pid int) (string, error) {
+	return "", fmt.Errorf("process working directory not found")
+}
+
+// GetProcessStartTime is not recorded by the fake
+func (f *FakeManager) GetProcessStartTime(ctx context.Context, pid int) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("process start time not found")
+}
+
+// GetProcessUser returns the user from Users
+func (f *FakeManager) GetProcessUser(ctx context.Context, pid int) (string, error) {
+	if f.Denied[pid] {
+		return "", fmt.Errorf("failed to get process user: permission denied")
+	}
+	if user := f.Users[pid]; user != "" {
+		return user, nil
+	}
+	return "", fmt.Errorf("process user not found")
+}
+
+// GetProcessContainer reports every process as a host process
+func (f *FakeManager) GetProcessContainer(ctx context.Context, pid int) (string, error) {
+	return "", nil
+}
+
+// ListProcesses returns the processes that have not been killed
+func (f *FakeManager) ListProcesses(ctx context.Context) ([]types.ProcessInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var processes []types.ProcessInfo
+	for _, proc := range f.Processes {
+		if !f.killed[proc.PID] {
+			processes = append(processes, proc)
+		}
+	}
+	return processes, nil
+}
+
+// ProcessStatus reports killed and unknown processes as gone and Denied ones as
+// permission denied
+func (f *FakeManager) ProcessStatus(ctx context.Context, pid int) types.FieldStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case f.killed[pid] || f.process(pid) == nil:
+		return types.StatusProcessGone
+	case f.Denied[pid]:
+		return types.StatusPermissionDenied
+	}
+	return types.StatusUnsupported
+}
+
+// alive reports whether the process has not been killed
+func (f *FakeManager) alive(pid int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.killed[pid]
+}
+
+// details returns the name from Processes and the path from Paths, except the paths
+// of Denied processes
+func (f *FakeManager) details(pid int) (name, path string) {
+	if proc := f.process(pid); proc != nil {
+		name = proc.Name
+	}
+	if !f.Denied[pid] {
+		path = f.Paths[pid]
+	}
+	return name, path
+}
+
+// process returns the process with the PID from the process table
+func (f *FakeManager) process(pid int) *types.ProcessInfo {
+	for i := range f.Processes {
+		if f.Processes[i].PID == pid {
+			return &f.Processes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package platformtest
+
+import (
+	"context"
+	"time"
+
+	"portreleasor/internal/types"
+)
+
+// Output formats a Replay can parse
+const (
+	// FormatSS is the output of `ss -tunap` on Linux
+	FormatSS = "ss"
+	// FormatNetstat is the output of `netstat -tunap` on Linux
+	FormatNetstat = "netstat"
+	// FormatLsof is the output of `lsof -i -P -n` on macOS
+	FormatLsof = "lsof"
+	// FormatNetstatANO is the output of `netstat -ano` on Windows
+	FormatNetstatANO = "netstat-ano"
+)
+
+// Replay is a captured tool listing, served through platform.Sockets
+type Replay struct {
+	// Format is the tool that produced Output, one of the Format constants
+	Format string
+	// Output is the captured socket listing
+	Output string
+	// Delay makes listing the sockets take this long, to test cancellation
+	Delay time.Duration
+	// Unprivileged reports listening sockets without owner as hidden, like ss does for
+	// other users' processes when not run as root
+	Unprivileged bool
+}
+
+// output returns Output after Delay, unless ctx is done first
+func (r Replay) output(ctx context.Context) (string, error) {
+	select {
+	case <-time.After(r.Delay):
+		return r.Output, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// unowned is the status of the listening sockets listed without owner
+func (r Replay) unowned() types.FieldStatus {
+	if r.Unprivileged {
+		return types.StatusPermissionDenied
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package platform
+
+// Operation is something the tool does that may need more privileges than the
+// current user has
+type Operation int
+
+const (
+	// OpInspect identifies the owners of sockets and reads the details of their processes
+	OpInspect Operation = iota
+	// OpKill terminates processes
+	OpKill
+	// OpNamespaces inspects the sockets of other network namespaces, e.g. containers
+	OpNamespaces
+)
+
+// Privilege is an OS privilege the tool may need, such as a Linux capability
+type Privilege struct {
+	// Name is the privilege, e.g. CAP_KILL, or root and Administrator on platforms
+	// without finer-grained privileges
+	Name string `json:"name"`
+	// Purpose is what the tool cannot do without the privilege
+	Purpose string `json:"purpose"`
+	// Held reports whether the current process has the privilege
+	Held bool `json:"held"`
+
+	ops []Operation
+}
+
+// MissingPrivileges returns the privileges op needs that the current process lacks
+func MissingPrivileges(op Operation) []Privilege {
+	var missing []Privilege
+	for _, privilege := range Privileges() {
+		if privilege.Held {
+			continue
+		}
+		for _, needed := range privilege.ops {
+			if needed == op {
+				missing = append(missing, privilege)
+				break
+			}
+		}
+	}
+	return missing
+}
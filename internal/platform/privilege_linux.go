@@ -0,0 +1,66 @@
+//go:build linux
+
+package platform
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 能力在 CapEff 位图中的位置，见 capabilities(7)
+const (
+	capKill           = 5
+	capNetBindService = 10
+	capNetAdmin       = 12
+	capSysPtrace      = 19
+)
+
+// Privileges 返回工具可能用到的Linux能力及当前进程是否拥有；root 拥有全部能力
+func Privileges() []Privilege {
+	return []Privilege{
+		{
+			Name:    "CAP_SYS_PTRACE",
+			Purpose: "identify the owners of other users' sockets and read their executables, command lines and working directories",
+			Held:    hasEffectiveCapability(capSysPtrace),
+			ops:     []Operation{OpInspect, OpNamespaces},
+		},
+		{
+			Name:    "CAP_KILL",
+			Purpose: "terminate processes owned by other users",
+			Held:    hasEffectiveCapability(capKill),
+			ops:     []Operation{OpKill},
+		},
+		{
+			Name:    "CAP_NET_ADMIN",
+			Purpose: "inspect the sockets of other network namespaces, e.g. containers",
+			Held:    hasEffectiveCapability(capNetAdmin),
+			ops:     []Operation{OpNamespaces},
+		},
+	}
+}
+
+// Elevated 判断当前进程是否以root运行
+func Elevated() bool {
+	return os.Geteuid() == 0
+}
+
+// hasEffectiveCapability 检查当前进程是否拥有指定的有效能力
+func hasEffectiveCapability(bit uint) bool {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "CapEff:") {
+			caps, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+			if err != nil {
+				return false
+			}
+			return caps&(1<<bit) != 0
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !windows
+
+package platform
+
+import "os"
+
+// Privileges 没有细粒度能力的平台上，检查和终止其他用户的进程都需要root
+func Privileges() []Privilege {
+	return []Privilege{{
+		Name:    "root",
+		Purpose: "see the sockets of other users' processes and terminate them",
+		Held:    Elevated(),
+		ops:     []Operation{OpInspect, OpKill, OpNamespaces},
+	}}
+}
+
+// Elevated 判断当前进程是否以root运行
+func Elevated() bool {
+	return os.Geteuid() == 0
+}
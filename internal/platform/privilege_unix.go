@@ -0,0 +1,22 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os/exec"
+
+	"portreleasor/internal/i18n"
+)
+
+// escalators 按优先顺序尝试的提权工具
+var escalators = []string{"sudo", "doas", "pkexec"}
+
+// FindEscalator 返回第一个可用的提权工具的路径
+func FindEscalator() (string, error) {
+	for _, name := range escalators {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", i18n.Errorf("none of sudo, doas or pkexec is installed; run as root instead")
+}
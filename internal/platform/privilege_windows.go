@@ -0,0 +1,28 @@
+//go:build windows
+
+package platform
+
+import (
+	"golang.org/x/sys/windows"
+	"portreleasor/internal/i18n"
+)
+
+// Privileges Windows上读取服务进程的路径和终止其他用户的进程需要管理员权限
+func Privileges() []Privilege {
+	return []Privilege{{
+		Name:    "Administrator",
+		Purpose: "read the paths of service processes and terminate processes of other users",
+		Held:    Elevated(),
+		ops:     []Operation{OpInspect, OpKill, OpNamespaces},
+	}}
+}
+
+// Elevated 判断当前进程是否以管理员身份运行：检查进程令牌是否已提升（UAC）
+func Elevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// FindEscalator Windows没有可在当前控制台中提权的命令
+func FindEscalator() (string, error) {
+	return "", i18n.Errorf("elevation is not supported on Windows; run from an Administrator prompt instead")
+}
@@ -0,0 +1,128 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"portreleasor/internal/types"
+)
+
+// SocketSource is the captured output of a socket listing tool and the lookups the
+// owners of its sockets are resolved with. The lookups are optional.
+type SocketSource struct {
+	// Format names the tool that produced the output: ss, netstat or lsof as run by the
+	// Linux and macOS managers, or netstat-ano for netstat -ano on Windows
+	Format string
+	// Output returns the tool output
+	Output func(ctx context.Context) (string, error)
+	// Alive reports whether a process of the listing still runs; sockets whose owners
+	// have all exited are dropped
+	Alive func(pid int) bool
+	// Process returns the name and path of a process, empty when unknown
+	Process func(pid int) (name, path string)
+	// Status explains a name or path that Process did not return
+	Status func(ctx context.Context, pid int) types.FieldStatus
+	// Unowned is the status of listening sockets listed without owner, empty for
+	// kernel sockets
+	Unowned types.FieldStatus
+}
+
+// Sockets discovers the sockets of a SocketSource through the pipeline shared by all
+// platforms, so that managers outside this package, such as platformtest.FakeManager,
+// exercise the same parsing, deduplication and owner resolution as the platform
+// managers. Doctor probes managers that return it from a Sockets method.
+type Sockets struct {
+	p *pipeline
+}
+
+// socketParsers are the parsers of the formats a SocketSource may have
+var socketParsers = map[string]func(io.Reader) ([]socketEntry, error){
+	"ss":          parseSS,
+	"netstat":     parseNetstat,
+	"lsof":        parseLsof,
+	"netstat-ano": parseNetstatANO,
+}
+
+// NewSockets returns the socket queries of the source
+func NewSockets(source SocketSource) *Sockets {
+	collect := func(ctx context.Context, listening bool) ([]socketEntry, error) {
+		parse := socketParsers[source.Format]
+		if parse == nil {
+			return nil, fmt.Errorf("unknown output format %q", source.Format)
+		}
+		out, err := source.Output(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := parse(strings.NewReader(out))
+		if err != nil || source.Alive == nil {
+			return entries, err
+		}
+		return aliveEntries(entries, source.Alive), nil
+	}
+
+	enrich := func(ctx context.Context, details map[int]*processDetails) error {
+		if source.Process == nil {
+			return nil
+		}
+		for pid, d := range details {
+			name, path := source.Process(pid)
+			if d.Name == "" {
+				d.Name = name
+			}
+			if d.Path == "" {
+				d.Path = path
+			}
+		}
+		return nil
+	}
+
+	return &Sockets{p: &pipeline{
+		collectors: []socketCollector{{name: source.Format, collect: collect}},
+		enrichers:  []processEnricher{enrich},
+		unowned:    source.Unowned,
+		status:     source.Status,
+	}}
+}
+
+// aliveEntries drops the sockets whose owners have all exited
+func aliveEntries(entries []socketEntry, alive func(pid int) bool) []socketEntry {
+	var kept []socketEntry
+	for _, entry := range entries {
+		var pids []int
+		for _, pid := range entry.PIDs {
+			if alive(pid) {
+				pids = append(pids, pid)
+			}
+		}
+		if len(entry.PIDs) > 0 && len(pids) == 0 {
+			continue
+		}
+		entry.PIDs = pids
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// GetPortConnections returns one listening socket per port and protocol
+func (s *Sockets) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return s.p.listening(ctx)
+}
+
+// GetListeningSockets returns one listening socket per port and protocol, named after
+// the process the tool output shows
+func (s *Sockets) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return s.p.sockets(ctx, true)
+}
+
+// ResolveProcesses fills in process names and paths
+func (s *Sockets) ResolveProcesses(ctx context.Context, sockets []types.PortInfo) {
+	s.p.resolve(ctx, sockets)
+}
+
+// GetAllSockets returns every socket, one entry per owning process
+func (s *Sockets) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return s.p.all(ctx)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package platform
+
+import "os"
+
+// OpenTerminal 打开控制终端，用于标准输入被管道占用时读取确认
+func OpenTerminal() (*os.File, error) {
+	return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+}
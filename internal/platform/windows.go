@@ -3,21 +3,19 @@
 package platform
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"os/exec"
-	"regexp"
-	"strconv"
-	"strings"
+	"time"
+
+	"portreleasor/internal/i18n"
 	"portreleasor/internal/types"
 )
 
-// WindowsManager Windows平台实现
-type WindowsManager struct {
-	processNameCache map[int]string
-	processPathCache map[int]string
-}
+// WindowsManager Windows平台实现，不保存状态，可并发使用
+type WindowsManager struct{}
 
 // Windows API 相关常量和结构体
 const (
@@ -39,267 +37,198 @@ type PROCESSENTRY32 struct {
 	szExeFile           [260]uint16
 }
 
-// initProcessCache 初始化进程缓存
-func (wm *WindowsManager) initProcessCache() {
-	if wm.processNameCache == nil {
-		wm.processNameCache = make(map[int]string)
-	}
-	if wm.processPathCache == nil {
-		wm.processPathCache = make(map[int]string)
+// pipeline 返回Windows的套接字发现流程：netstat 列出套接字，tasklist 补全进程名称，wmic 补全进程路径
+func (wm *WindowsManager) pipeline() *pipeline {
+	return &pipeline{
+		collectors: []socketCollector{
+			toolCollector("netstat", fixedArgs("-ano"), parseNetstatANO),
+		},
+		enrichers: []processEnricher{wm.enrichFromTasklist, wm.enrichFromWmic},
+		status:    wm.ProcessStatus,
 	}
 }
 
-// getAllProcessInfo 批量获取所有进程信息（名称和路径）
-func (wm *WindowsManager) getAllProcessInfo() error {
-	wm.initProcessCache()
-
-	// 使用tasklist获取进程名称和PID
-	cmd := exec.Command("tasklist", "/FO", "CSV", "/NH")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to execute tasklist: %v", err)
+// enrichFromTasklist 使用tasklist批量获取进程名称
+func (wm *WindowsManager) enrichFromTasklist(ctx context.Context, details map[int]*processDetails) error {
+	out, err := runCommand(ctx, "tasklist", "/FO", "CSV", "/NH")
+	if err != nil {
+		return i18n.Errorf("failed to execute %s: %w", "tasklist", err)
 	}
 
-	lines := strings.Split(out.String(), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	names, err := parseTasklist(out)
+	if err != nil {
+		return i18n.Errorf("failed to parse %s output: %w", "tasklist", err)
+	}
 
-		fields := strings.Split(line, ",")
-		if len(fields) >= 2 {
-			name := strings.Trim(fields[0], "\"")
-			pidStr := strings.Trim(fields[1], "\"")
-			if pid, err := strconv.Atoi(pidStr); err == nil {
-				wm.processNameCache[pid] = name
-			}
+	for pid, d := range details {
+		if d.Name == "" {
+			d.Name = names[pid]
 		}
 	}
+	return nil
+}
 
-	// 使用wmic获取进程路径信息
-	cmd2 := exec.Command("wmic", "process", "get", "ProcessId,ExecutablePath", "/format:csv")
-	var out2 bytes.Buffer
-	cmd2.Stdout = &out2
-
-	if err := cmd2.Run(); err != nil {
-		// wmic失败时不影响进程名称获取，只返回警告
-		return nil
+// enrichFromWmic 使用wmic批量获取进程路径
+func (wm *WindowsManager) enrichFromWmic(ctx context.Context, details map[int]*processDetails) error {
+	out, err := runCommand(ctx, "wmic", "process", "get", "ProcessId,ExecutablePath", "/format:csv")
+	if err != nil {
+		return i18n.Errorf("failed to execute %s: %w", "wmic", err)
 	}
 
-	lines2 := strings.Split(out2.String(), "\n")
-	for _, line := range lines2 {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "Node") {
-			continue
-		}
+	paths, err := parseWmicPaths(out)
+	if err != nil {
+		return i18n.Errorf("failed to parse %s output: %w", "wmic", err)
+	}
 
-		fields := strings.Split(line, ",")
-		if len(fields) >= 2 {
-			path := strings.Trim(fields[1], "\"")
-			pidStr := strings.Trim(fields[2], "\"")
-			if pid, err := strconv.Atoi(pidStr); err == nil && path != "" {
-				wm.processPathCache[pid] = path
-			}
+	for pid, d := range details {
+		if d.Path == "" {
+			d.Path = paths[pid]
 		}
 	}
-
 	return nil
 }
-// GetPortConnections 获取Windows系统端口连接信息
-func (wm *WindowsManager) GetPortConnections() ([]types.PortInfo, error) {
-	// 首先批量获取所有进程信息
-	if err := wm.getAllProcessInfo(); err != nil {
-		return nil, fmt.Errorf("failed to get process info: %v", err)
-	}
 
-	cmd := exec.Command("netstat", "-ano")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to execute netstat: %v", err)
-	}
-
-	var connections []types.PortInfo
-	lines := strings.Split(out.String(), "\n")
-
-	re := regexp.MustCompile(`\s+`)
-	portMap := make(map[string]types.PortInfo) // key: "port:protocol"
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || !strings.HasPrefix(line, "TCP") && !strings.HasPrefix(line, "UDP") {
-			continue
-		}
+// GetPortConnections 获取Windows系统端口连接信息
+func (wm *WindowsManager) GetPortConnections(ctx context.Context) ([]types.PortInfo, error) {
+	return wm.pipeline().listening(ctx)
+}
 
-		fields := re.Split(line, -1)
-		if len(fields) < 4 {
-			continue
-		}
+// GetListeningSockets 获取Windows系统监听端口，不解析所属进程
+func (wm *WindowsManager) GetListeningSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return wm.pipeline().sockets(ctx, true)
+}
 
-		protocol := fields[0]
-		localAddr := fields[1]
-		var pid int
-		var state string
-
-		if protocol == "TCP" {
-			if len(fields) >= 5 {
-				state = fields[3]
-				pidStr := fields[4]
-				var err error
-				pid, err = strconv.Atoi(pidStr)
-				if err != nil {
-					continue
-				}
-			}
-		} else if protocol == "UDP" {
-			if len(fields) >= 4 {
-				pidStr := fields[3]
-				var err error
-				pid, err = strconv.Atoi(pidStr)
-				if err != nil {
-					continue
-				}
-				state = "LISTENING"
-			}
-		}
+// ResolveProcesses 解析套接字所属进程及其名称和路径
+func (wm *WindowsManager) ResolveProcesses(ctx context.Context, sockets []types.PortInfo) {
+	wm.pipeline().resolve(ctx, sockets)
+}
 
-		parts := strings.Split(localAddr, ":")
-		if len(parts) < 2 {
-			continue
-		}
+// KillProcessByPID 在Windows上杀死指定PID的进程
+func (wm *WindowsManager) KillProcessByPID(ctx context.Context, pid int) error {
+	// 使用syscall.kill
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return i18n.Errorf("failed to find process %d: %w", pid, err)
+	}
 
-		portStr := parts[len(parts)-1]
-		port, err := strconv.Atoi(portStr)
-		if err != nil {
-			continue
-		}
+	err = proc.Kill()
+	if err != nil {
+		return i18n.Errorf("failed to kill process %d: %w", pid, err)
+	}
 
-		processName := wm.getProcessNameFromCache(pid)
-		processPath := wm.getProcessPathFromCache(pid)
-		key := fmt.Sprintf("%d:%s", port, protocol)
-
-		// 创建新的端口信息
-		newInfo := types.PortInfo{
-			Port:        port,
-			Protocol:    protocol,
-			PID:         pid,
-			ProcessName: processName,
-			ProcessPath: processPath,
-			LocalAddr:   localAddr,
-			State:       state,
-		}
+	return nil
+}
 
-		// 检查是否已存在该端口的记录
-		if _, exists := portMap[key]; exists {
-			// 如果新连接是 LISTENING 状态，优先使用
-			if state == "LISTENING" {
-				portMap[key] = newInfo
-			}
-			// 否则保持现有的记录
-		} else {
-			// 如果不存在，直接添加
-			portMap[key] = newInfo
-		}
+// GetProcessPath 获取Windows进程路径
+func (wm *WindowsManager) GetProcessPath(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "wmic", "process", "where", fmt.Sprintf("ProcessId=%d", pid), "get", "ExecutablePath", "/format:list")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process path: %v", err)
 	}
 
-	// 将 map 转换为 slice
-	for _, info := range portMap {
-		connections = append(connections, info)
+	path, err := parseWmicValue(out, "ExecutablePath")
+	if err != nil {
+		return "", i18n.Errorf("process path not found")
 	}
 
-	return connections, nil
+	return path, nil
 }
 
-// getProcessNameFromCache 从缓存获取进程名称
-func (wm *WindowsManager) getProcessNameFromCache(pid int) string {
-	wm.initProcessCache()
-	if name, exists := wm.processNameCache[pid]; exists {
-		return name
+// GetProcessCommandLine 获取Windows进程完整命令行
+func (wm *WindowsManager) GetProcessCommandLine(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "wmic", "process", "where", fmt.Sprintf("ProcessId=%d", pid), "get", "CommandLine", "/format:list")
+	if err != nil {
+		return "", i18n.Errorf("failed to get process command line: %v", err)
 	}
-	return "Unknown"
-}
 
-// getProcessPathFromCache 从缓存获取进程路径
-func (wm *WindowsManager) getProcessPathFromCache(pid int) string {
-	wm.initProcessCache()
-	if path, exists := wm.processPathCache[pid]; exists {
-		return path
+	cmdline, err := parseWmicValue(out, "CommandLine")
+	if err != nil {
+		return "", i18n.Errorf("process command line not found")
 	}
-	return ""
+
+	return cmdline, nil
 }
-func (wm *WindowsManager) getProcessName(pid int) string {
-	cmd := exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH")
-	var out bytes.Buffer
-	cmd.Stdout = &out
 
-	if err := cmd.Run(); err != nil {
-		return "Unknown"
+// GetProcessCwd 获取Windows进程工作目录
+func (wm *WindowsManager) GetProcessCwd(ctx context.Context, pid int) (string, error) {
+	// 读取其他进程的工作目录需要访问其PEB，命令行工具无法提供
+	return "", i18n.Errorf("process working directory is %w on Windows", ErrUnsupported)
+}
+
+// GetProcessStartTime 获取Windows进程启动时间
+func (wm *WindowsManager) GetProcessStartTime(ctx context.Context, pid int) (time.Time, error) {
+	out, err := runCommand(ctx, "wmic", "process", "where", fmt.Sprintf("ProcessId=%d", pid), "get", "CreationDate", "/format:list")
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to get process start time: %v", err)
 	}
 
-	output := strings.TrimSpace(out.String())
-	if output == "" {
-		return "Unknown"
+	value, err := parseWmicValue(out, "CreationDate")
+	if err != nil {
+		return time.Time{}, i18n.Errorf("process start time not found")
 	}
 
-	fields := strings.Split(output, ",")
-	if len(fields) > 0 {
-		name := strings.Trim(fields[0], "\"")
-		return name
+	startTime, err := parseCIMDateTime(value)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("failed to parse process start time: %v", err)
 	}
 
-	return "Unknown"
+	return startTime, nil
 }
 
-// KillProcessByPID 在Windows上杀死指定PID的进程
-func (wm *WindowsManager) KillProcessByPID(pid int) error {
-	// 使用syscall.kill
-	proc, err := os.FindProcess(pid)
+// GetProcessUser 获取Windows进程所属用户
+func (wm *WindowsManager) GetProcessUser(ctx context.Context, pid int) (string, error) {
+	out, err := runCommand(ctx, "tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/V", "/FO", "CSV", "/NH")
 	if err != nil {
-		return fmt.Errorf("无法找到进程 %d: %v", pid, err)
+		return "", i18n.Errorf("failed to get process user: %v", err)
 	}
 
-	err = proc.Kill()
+	return parseTasklistUser(out)
+}
+
+// GetProcessContainer Windows容器检测暂不支持，均视为主机进程
+func (wm *WindowsManager) GetProcessContainer(ctx context.Context, pid int) (string, error) {
+	return "", nil
+}
+
+// ProcessStatus 判断Windows进程信息无法读取的原因：无法打开进程句柄时，拒绝访问说明权限不足
+// （如系统服务），其他错误说明进程已退出
+func (wm *WindowsManager) ProcessStatus(ctx context.Context, pid int) types.FieldStatus {
+	proc, err := os.FindProcess(pid)
 	if err != nil {
-		return fmt.Errorf("无法杀死进程 %d: %v", pid, err)
+		if errors.Is(err, fs.ErrPermission) {
+			return types.StatusPermissionDenied
+		}
+		return types.StatusProcessGone
 	}
-
-	return nil
+	proc.Release()
+	return types.StatusUnsupported
 }
 
-// GetProcessPath 获取Windows进程路径
-func (wm *WindowsManager) GetProcessPath(pid int) (string, error) {
-	cmd := exec.Command("wmic", "process", "where", fmt.Sprintf("ProcessId=%d", pid), "get", "ExecutablePath", "/format:list")
-	var out bytes.Buffer
-	cmd.Stdout = &out
+// GetAllSockets 获取Windows系统所有套接字（监听和已建立的连接），不做去重
+func (wm *WindowsManager) GetAllSockets(ctx context.Context) ([]types.PortInfo, error) {
+	return wm.pipeline().all(ctx)
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to get process path: %v", err)
+// ListProcesses 获取Windows系统所有进程及其父进程
+func (wm *WindowsManager) ListProcesses(ctx context.Context) ([]types.ProcessInfo, error) {
+	out, err := runCommand(ctx, "wmic", "process", "get", "Name,ParentProcessId,ProcessId", "/format:csv")
+	if err != nil {
+		return nil, i18n.Errorf("failed to execute %s: %w", "wmic", err)
 	}
 
-	output := strings.TrimSpace(out.String())
-	lines := strings.Split(output, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "ExecutablePath=") {
-			path := strings.TrimPrefix(line, "ExecutablePath=")
-			path = strings.TrimSpace(path)
-			if path != "" {
-				return path, nil
-			}
-		}
+	processes, err := parseWmicProcesses(out)
+	if err != nil {
+		return nil, i18n.Errorf("failed to parse %s output: %w", "wmic", err)
 	}
 
-	return "", fmt.Errorf("process path not found")
+	return processes, nil
 }
 
 func init() {
 	// 注册Windows管理器
+	// 所有调用方共享同一个实例，短时间内的重复查询复用同一次扫描
+	manager := NewCachedManager(&WindowsManager{}, DefaultCacheTTL)
 	GetPlatformManager = func() PlatformManager {
-		return &WindowsManager{}
+		return manager
 	}
 }
\ No newline at end of file
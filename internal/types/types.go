@@ -2,18 +2,86 @@ package types
 
 import (
 	"fmt"
+	"sort"
+	"time"
 )
 
 // PortInfo represents port usage information
 type PortInfo struct {
-	Port       int    `json:"port"`
-	Protocol   string `json:"protocol"`
-	PID        int    `json:"pid"`
+	Port        int    `json:"port"`
+	Protocol    string `json:"protocol"`
+	PID         int    `json:"pid"`
 	ProcessName string `json:"process_name"`
 	ProcessPath string `json:"process_path"`
-	LocalAddr  string `json:"local_addr"`
-	RemoteAddr string `json:"remote_addr"`
-	State      string `json:"state"`
+	LocalAddr   string `json:"local_addr"`
+	RemoteAddr  string `json:"remote_addr"`
+	State       string `json:"state"`
+	// User is the name of the user owning the process
+	User string `json:"user,omitempty"`
+	// Container identifies the container the process runs in, e.g. "docker/4f2a9c1b7d3e"
+	Container string `json:"container,omitempty"`
+	// CommandLine is the full command line of the process
+	CommandLine string `json:"command_line,omitempty"`
+	// Cwd is the working directory of the process
+	Cwd string `json:"cwd,omitempty"`
+	// StartTime is when the process was started
+	StartTime *time.Time `json:"start_time,omitempty"`
+	// Service is the registered service name of the port, e.g. "ssh"
+	Service string `json:"service,omitempty"`
+	// ServiceMismatch is set when a well-known port is served by an unexpected daemon
+	ServiceMismatch bool `json:"service_mismatch,omitempty"`
+	// Status records how each process field that was looked up got resolved, keyed by
+	// the field's JSON name, e.g. {"process_path": "permission-denied"}
+	Status map[string]FieldStatus `json:"status,omitempty"`
+}
+
+// FieldStatus is the outcome of looking up one process field of a PortInfo
+type FieldStatus string
+
+const (
+	// StatusOK means the field was resolved; it may still be empty, e.g. a host process has no container
+	StatusOK FieldStatus = "ok"
+	// StatusPermissionDenied means the field belongs to a process the current user may not inspect
+	StatusPermissionDenied FieldStatus = "permission-denied"
+	// StatusProcessGone means the process exited before the field was read
+	StatusProcessGone FieldStatus = "process-gone"
+	// StatusUnsupported means the platform or its tools cannot provide the field
+	StatusUnsupported FieldStatus = "unsupported"
+)
+
+// Process fields with a resolution status, named after their JSON keys
+const (
+	FieldPID         = "pid"
+	FieldProcessName = "process_name"
+	FieldProcessPath = "process_path"
+	FieldCommandLine = "command_line"
+	FieldCwd         = "cwd"
+	FieldStartTime   = "start_time"
+	FieldUser        = "user"
+	FieldContainer   = "container"
+)
+
+// SetStatus records the resolution status of a field
+func (p *PortInfo) SetStatus(field string, status FieldStatus) {
+	if p.Status == nil {
+		p.Status = make(map[string]FieldStatus)
+	}
+	p.Status[field] = status
+}
+
+// StatusOf returns the resolution status of a field, empty if it was not looked up
+func (p PortInfo) StatusOf(field string) FieldStatus {
+	return p.Status[field]
+}
+
+// Denied reports whether any field could not be resolved for lack of privileges
+func (p PortInfo) Denied() bool {
+	for _, status := range p.Status {
+		if status == StatusPermissionDenied {
+			return true
+		}
+	}
+	return false
 }
 
 // String returns the string representation of PortInfo
@@ -25,3 +93,40 @@ func (p PortInfo) String() string {
 	return fmt.Sprintf("%d/%s\t%d\t%s",
 		p.Port, p.Protocol, p.PID, p.ProcessName)
 }
+
+// Less reports whether p sorts before o in the default order:
+// port, then protocol, then local address, then remote address
+func (p PortInfo) Less(o PortInfo) bool {
+	if p.Port != o.Port {
+		return p.Port < o.Port
+	}
+	if p.Protocol != o.Protocol {
+		return p.Protocol < o.Protocol
+	}
+	if p.LocalAddr != o.LocalAddr {
+		return p.LocalAddr < o.LocalAddr
+	}
+	return p.RemoteAddr < o.RemoteAddr
+}
+
+// SortPortInfos sorts port records in the default order
+func SortPortInfos(list []PortInfo) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Less(list[j])
+	})
+}
+
+// ProcessInfo represents basic process information
+type ProcessInfo struct {
+	PID  int    `json:"pid"`
+	PPID int    `json:"ppid"`
+	Name string `json:"name"`
+}
+
+// Finding represents one diagnosed reason why a port may be unavailable
+type Finding struct {
+	Check       string   `json:"check"`
+	Summary     string   `json:"summary"`
+	Details     []string `json:"details,omitempty"`
+	Remediation string   `json:"remediation"`
+}
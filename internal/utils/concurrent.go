@@ -0,0 +1,32 @@
+package utils
+
+import "sync"
+
+// maxWorkers 并发查询进程信息时的最大协程数，避免同时启动过多外部命令
+const maxWorkers = 8
+
+// ForEach 并发地对 0 到 n-1 的每个下标调用 fn，全部完成后返回
+func ForEach(n int, fn func(i int)) {
+	workers := maxWorkers
+	if n < workers {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -1,22 +1,31 @@
 package utils
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+
+	"portreleasor/internal/i18n"
 )
 
-// ParsePorts 解析端口参数，支持单个端口、多个端口、端口范围
+// ParsePorts 解析端口参数，支持单个端口、多个端口、端口范围和服务名（如 ssh、http-alt）
 func ParsePorts(ports []string) ([]int, error) {
 	var result []int
 	seen := make(map[int]bool)
 
 	for _, portInput := range ports {
-		if strings.Contains(portInput, "-") {
+		if servicePorts := LookupServicePorts(portInput); len(servicePorts) > 0 {
+			// 处理服务名，如 "http-alt"
+			for _, port := range servicePorts {
+				if !seen[port] {
+					result = append(result, port)
+					seen[port] = true
+				}
+			}
+		} else if strings.Contains(portInput, "-") {
 			// 处理端口范围，如 "8080-8090"
 			rangePorts, err := parsePortRange(portInput)
 			if err != nil {
-				return nil, fmt.Errorf("无效的端口范围 '%s': %v", portInput, err)
+				return nil, i18n.Errorf("invalid port range '%s': %v", portInput, err)
 			}
 			for _, port := range rangePorts {
 				if !seen[port] {
@@ -28,10 +37,10 @@ func ParsePorts(ports []string) ([]int, error) {
 			// 处理单个端口
 			port, err := strconv.Atoi(portInput)
 			if err != nil {
-				return nil, fmt.Errorf("无效的端口号 '%s': %v", portInput, err)
+				return nil, i18n.Errorf("invalid port number '%s': %v", portInput, err)
 			}
 			if port < 1 || port > 65535 {
-				return nil, fmt.Errorf("端口号 %d 超出范围 (1-65535)", port)
+				return nil, i18n.Errorf("port %d out of range (1-65535)", port)
 			}
 			if !seen[port] {
 				result = append(result, port)
@@ -47,25 +56,25 @@ func ParsePorts(ports []string) ([]int, error) {
 func parsePortRange(rangeStr string) ([]int, error) {
 	parts := strings.Split(rangeStr, "-")
 	if len(parts) != 2 {
-		return nil, fmt.Errorf("端口范围格式错误")
+		return nil, i18n.Errorf("malformed port range")
 	}
 
 	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
 	if err != nil {
-		return nil, fmt.Errorf("无效的起始端口: %v", err)
+		return nil, i18n.Errorf("invalid start port: %v", err)
 	}
 
 	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
 	if err != nil {
-		return nil, fmt.Errorf("无效的结束端口: %v", err)
+		return nil, i18n.Errorf("invalid end port: %v", err)
 	}
 
 	if start < 1 || end > 65535 {
-		return nil, fmt.Errorf("端口范围超出有效范围 (1-65535)")
+		return nil, i18n.Errorf("port range out of range (1-65535)")
 	}
 
 	if start > end {
-		return nil, fmt.Errorf("起始端口不能大于结束端口")
+		return nil, i18n.Errorf("start port is greater than end port")
 	}
 
 	var result []int
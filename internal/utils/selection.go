@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/i18n"
+)
+
+// ParseSelection 解析交互式选择输入，支持 "1,3-4" 形式的编号列表
+// 返回按输入顺序去重后的编号（从1开始，不超过 max）
+func ParseSelection(input string, max int) ([]int, error) {
+	var result []int
+	seen := make(map[int]bool)
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		start, end := part, part
+		if strings.Contains(part, "-") {
+			bounds := strings.SplitN(part, "-", 2)
+			start, end = strings.TrimSpace(bounds[0]), strings.TrimSpace(bounds[1])
+		}
+
+		from, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, i18n.Errorf("invalid number '%s'", part)
+		}
+		to, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, i18n.Errorf("invalid number '%s'", part)
+		}
+
+		if from > to {
+			return nil, i18n.Errorf("start number is greater than end number '%s'", part)
+		}
+		if from < 1 || to > max {
+			return nil, i18n.Errorf("number '%s' out of range (1-%d)", part, max)
+		}
+
+		for i := from; i <= to; i++ {
+			if !seen[i] {
+				result = append(result, i)
+				seen[i] = true
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, i18n.Errorf("no number selected")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelection(t *testing.T) {
+	for _, tt := range []struct {
+		input   string
+		max     int
+		want    []int
+		wantErr string
+	}{
+		{input: "1", max: 3, want: []int{1}},
+		{input: "1,3-4", max: 5, want: []int{1, 3, 4}},
+		{input: "3,1-2", max: 3, want: []int{3, 1, 2}},
+		{input: " 2 , 4 - 5 ", max: 5, want: []int{2, 4, 5}},
+		{input: "2,1-3,2", max: 3, want: []int{2, 1, 3}},
+		{input: "1,,2,", max: 2, want: []int{1, 2}},
+		{input: "2-2", max: 2, want: []int{2}},
+		{input: "", max: 3, wantErr: "no number selected"},
+		{input: " , ", max: 3, wantErr: "no number selected"},
+		{input: "4-2", max: 5, wantErr: "start number is greater than end number '4-2'"},
+		{input: "0", max: 3, wantErr: "number '0' out of range (1-3)"},
+		{input: "2-4", max: 3, wantErr: "number '2-4' out of range (1-3)"},
+		{input: "a", max: 3, wantErr: "invalid number 'a'"},
+		{input: "1-", max: 3, wantErr: "invalid number '1-'"},
+		{input: "-1", max: 3, wantErr: "invalid number '-1'"},
+	} {
+		got, err := ParseSelection(tt.input, tt.max)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseSelection(%q, %d) error = %v, want %q", tt.input, tt.max, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSelection(%q, %d) error = %v", tt.input, tt.max, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSelection(%q, %d) = %v, want %v", tt.input, tt.max, got, tt.want)
+		}
+	}
+}
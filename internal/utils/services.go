@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed services.txt
+var embeddedServices string
+
+// serviceTable 端口与服务名的双向索引
+type serviceTable struct {
+	byPort map[string]string // key: "port/protocol"
+	byName map[string][]int
+}
+
+var (
+	services     *serviceTable
+	servicesOnce sync.Once
+)
+
+// expectedDaemons 常见知名端口上预期运行的守护进程
+var expectedDaemons = map[int][]string{
+	21:    {"vsftpd", "proftpd", "pure-ftpd", "ftpd"},
+	22:    {"sshd", "dropbear"},
+	25:    {"master", "postfix", "exim", "exim4", "sendmail", "smtpd"},
+	53:    {"named", "dnsmasq", "systemd-resolve", "unbound", "coredns", "pdns_server", "dns"},
+	80:    {"nginx", "httpd", "apache2", "caddy", "haproxy", "traefik", "envoy", "lighttpd", "w3wp", "system"},
+	111:   {"rpcbind", "portmap"},
+	123:   {"ntpd", "chronyd", "systemd-timesyncd", "timed", "w32time", "svchost"},
+	389:   {"slapd", "lsass"},
+	443:   {"nginx", "httpd", "apache2", "caddy", "haproxy", "traefik", "envoy", "lighttpd", "w3wp", "system"},
+	3306:  {"mysqld", "mariadbd"},
+	5432:  {"postgres", "postmaster"},
+	6379:  {"redis-server", "redis"},
+	11211: {"memcached"},
+	27017: {"mongod", "mongos"},
+}
+
+// portForwarders 会代为监听任意端口的转发程序，不视为异常
+var portForwarders = map[string]bool{
+	"docker-proxy":       true,
+	"rootlessport":       true,
+	"conmon":             true,
+	"slirp4netns":        true,
+	"kubectl":            true,
+	"sshd":               true,
+	"com.docker.backend": true,
+	"vpnkit":             true,
+	"wslhost":            true,
+}
+
+// loadServices 加载系统服务表，不可用时回退到内置表
+func loadServices() *serviceTable {
+	servicesOnce.Do(func() {
+		services = &serviceTable{
+			byPort: make(map[string]string),
+			byName: make(map[string][]int),
+		}
+
+		if f, err := os.Open(servicesFilePath()); err == nil {
+			services.parse(f)
+			f.Close()
+		}
+		// 内置表补充系统表中缺失的条目
+		services.parse(strings.NewReader(embeddedServices))
+	})
+	return services
+}
+
+// servicesFilePath 返回当前系统的服务文件路径
+func servicesFilePath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "etc", "services")
+	}
+	return "/etc/services"
+}
+
+// parse 解析 /etc/services 格式: name port/protocol [aliases...] [# comment]
+func (t *serviceTable) parse(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		portProto := strings.SplitN(fields[1], "/", 2)
+		if len(portProto) != 2 {
+			continue
+		}
+		port, err := strconv.Atoi(portProto[0])
+		if err != nil || port < 1 || port > 65535 {
+			continue
+		}
+
+		key := portProto[0] + "/" + strings.ToLower(portProto[1])
+		if _, exists := t.byPort[key]; !exists {
+			t.byPort[key] = fields[0]
+		}
+
+		names := append([]string{fields[0]}, fields[2:]...)
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if !containsInt(t.byName[name], port) {
+				t.byName[name] = append(t.byName[name], port)
+			}
+		}
+	}
+}
+
+// LookupService 返回端口对应的注册服务名，未注册时返回空字符串
+func LookupService(port int, protocol string) string {
+	return loadServices().byPort[strconv.Itoa(port)+"/"+strings.ToLower(protocol)]
+}
+
+// LookupServicePorts 返回服务名（或别名）对应的端口
+func LookupServicePorts(name string) []int {
+	return loadServices().byName[strings.ToLower(name)]
+}
+
+// IsUnexpectedDaemon 检查知名端口上运行的进程是否不是预期的守护进程
+func IsUnexpectedDaemon(port int, processName string) bool {
+	expected, known := expectedDaemons[port]
+	if !known || processName == "" {
+		return false
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(processName), ".exe")
+	if portForwarders[name] {
+		return false
+	}
+	for _, daemon := range expected {
+		// ps 的 comm 会截断为15个字符，如 systemd-resolved
+		if strings.HasPrefix(name, daemon) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpectedDaemons 返回知名端口上预期运行的守护进程
+func ExpectedDaemons(port int) []string {
+	return expectedDaemons[port]
+}
+
+// containsInt 检查切片中是否包含指定值
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"portreleasor/internal/i18n"
+)
+
+// PortTarget 释放目标：端口、端口范围或服务名，可限定协议和本地地址或网卡，
+// 如 8080/tcp、53/udp、127.0.0.1:8080、[::1]:9000、docker0:5432，* 表示未指定地址
+type PortTarget struct {
+	Ports []int
+	// Protocol 为 TCP 或 UDP，为空时匹配两者
+	Protocol string
+	// Address 为套接字绑定的 IP 地址或网卡名，为空时匹配任意地址
+	Address string
+
+	// ips 为 Address 对应的 IP 地址，网卡为其全部地址
+	ips []net.IP
+	// zones 为网卡的名称和编号，匹配绑定到网卡的套接字，如 127.0.0.53%lo
+	zones []string
+}
+
+// ParseTargets 解析释放目标，去掉重复的目标
+func ParseTargets(inputs []string) ([]PortTarget, error) {
+	var targets []PortTarget
+	seen := make(map[string]bool)
+
+	for _, input := range inputs {
+		target, err := ParseTarget(input)
+		if err != nil {
+			return nil, err
+		}
+		if key := target.String(); !seen[key] {
+			seen[key] = true
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, nil
+}
+
+// ParseTarget 解析单个释放目标，格式为 [地址或网卡:]端口[/协议]，IPv6 地址需放在方括号中
+func ParseTarget(input string) (PortTarget, error) {
+	var target PortTarget
+	spec := input
+
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		target.Protocol = strings.ToUpper(spec[i+1:])
+		if target.Protocol != "TCP" && target.Protocol != "UDP" {
+			return target, i18n.Errorf("invalid protocol %q in '%s': must be tcp or udp", spec[i+1:], input)
+		}
+		spec = spec[:i]
+	}
+
+	var host string
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]")
+		if end < 0 || !strings.HasPrefix(spec[end+1:], ":") {
+			return target, i18n.Errorf("invalid address in '%s': expected [address]:port", input)
+		}
+		host, spec = spec[1:end], spec[end+2:]
+		if net.ParseIP(stripZone(host)) == nil {
+			return target, i18n.Errorf("invalid IPv6 address %q in '%s'", host, input)
+		}
+	} else if i := strings.LastIndex(spec, ":"); i >= 0 {
+		host, spec = spec[:i], spec[i+1:]
+		if strings.Contains(host, ":") {
+			return target, i18n.Errorf("IPv6 address in '%s' must be enclosed in brackets, e.g. [::1]:9000", input)
+		}
+		if host == "" {
+			return target, i18n.Errorf("missing address before the port in '%s'", input)
+		}
+	}
+
+	if host != "" {
+		if err := target.resolveHost(host); err != nil {
+			return target, err
+		}
+	}
+
+	ports, err := ParsePorts([]string{spec})
+	if err != nil {
+		return target, err
+	}
+	target.Ports = ports
+	return target, nil
+}
+
+// resolveHost 将地址或网卡名解析为要匹配的 IP 地址
+func (t *PortTarget) resolveHost(host string) error {
+	t.Address = host
+
+	// 与 lsof 的写法相同，* 匹配绑定到 0.0.0.0 或 :: 的套接字
+	if host == "*" {
+		t.ips = []net.IP{net.IPv4zero, net.IPv6unspecified}
+		return nil
+	}
+	if ip := net.ParseIP(stripZone(host)); ip != nil {
+		t.ips = []net.IP{ip}
+		return nil
+	}
+
+	iface, err := net.InterfaceByName(host)
+	if err != nil {
+		return i18n.Errorf("unknown address or interface %q", host)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return i18n.Errorf("failed to get the addresses of interface %s: %v", host, err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			t.ips = append(t.ips, ipNet.IP)
+		}
+	}
+	t.zones = []string{iface.Name, strconv.Itoa(iface.Index)}
+	return nil
+}
+
+// Match 检查套接字是否为该目标，localAddr 为 ss、netstat 或 lsof 输出的本地地址，
+// 如 0.0.0.0:8080、[::]:22、:::8080、*:22、127.0.0.53%lo:53
+func (t PortTarget) Match(port int, protocol, localAddr string) bool {
+	if !containsInt(t.Ports, port) {
+		return false
+	}
+	// Linux 上 netstat 的 IPv6 套接字协议为 tcp6、udp6
+	if t.Protocol != "" && !strings.EqualFold(strings.TrimSuffix(protocol, "6"), t.Protocol) {
+		return false
+	}
+	if t.Address == "" {
+		return true
+	}
+
+	host, zone := splitLocalHost(localAddr)
+	if zone != "" {
+		for _, z := range t.zones {
+			if z == zone {
+				return true
+			}
+		}
+	}
+
+	ip := net.ParseIP(host)
+	for _, target := range t.ips {
+		// lsof 用 * 表示未指定地址
+		if ip == nil && host == "*" && target.IsUnspecified() || ip != nil && ip.Equal(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回目标的文本形式，如 127.0.0.1:8080/TCP
+func (t PortTarget) String() string {
+	ports := make([]string, len(t.Ports))
+	for i, port := range t.Ports {
+		ports[i] = strconv.Itoa(port)
+	}
+	text := strings.Join(ports, ",")
+	if t.Address != "" {
+		text = net.JoinHostPort(t.Address, text)
+	}
+	if t.Protocol != "" {
+		text += "/" + t.Protocol
+	}
+	return text
+}
+
+// splitLocalHost 返回本地地址中的主机和区域（网卡），去掉端口和方括号，
+// 区域可在方括号内外，如 [fe80::1%eth0]:22 和 ss 的 [fe80::1]%eth0:22
+func splitLocalHost(localAddr string) (string, string) {
+	host := localAddr
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+	host, zone, _ := strings.Cut(host, "%")
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	zone = strings.TrimSuffix(zone, "]")
+	return host, zone
+}
+
+// stripZone 去掉 IPv6 地址的区域标识，如 fe80::1%eth0
+func stripZone(host string) string {
+	host, _, _ = strings.Cut(host, "%")
+	return host
+}
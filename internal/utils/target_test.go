@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	for _, tt := range []struct {
+		input    string
+		ports    []int
+		protocol string
+		address  string
+		wantErr  string
+	}{
+		{input: "8080", ports: []int{8080}},
+		{input: "8080/tcp", ports: []int{8080}, protocol: "TCP"},
+		{input: "53/UDP", ports: []int{53}, protocol: "UDP"},
+		{input: "53/Udp", ports: []int{53}, protocol: "UDP"},
+		{input: "8000-8002/tcp", ports: []int{8000, 8001, 8002}, protocol: "TCP"},
+		{input: "127.0.0.1:8080", ports: []int{8080}, address: "127.0.0.1"},
+		{input: "[::1]:9000/tcp", ports: []int{9000}, protocol: "TCP", address: "::1"},
+		{input: "[fe80::1%eth0]:22", ports: []int{22}, address: "fe80::1%eth0"},
+		{input: "*:8080", ports: []int{8080}, address: "*"},
+		{input: "8080/sctp", wantErr: `invalid protocol "sctp" in '8080/sctp': must be tcp or udp`},
+		{input: "8080/", wantErr: `invalid protocol "" in '8080/': must be tcp or udp`},
+		{input: "::1:8080", wantErr: "IPv6 address in '::1:8080' must be enclosed in brackets, e.g. [::1]:9000"},
+		{input: "fe80::1%eth0:22", wantErr: "IPv6 address in 'fe80::1%eth0:22' must be enclosed in brackets, e.g. [::1]:9000"},
+		{input: ":8080", wantErr: "missing address before the port in ':8080'"},
+		{input: "[::1:8080", wantErr: "invalid address in '[::1:8080': expected [address]:port"},
+		{input: "[::1]8080", wantErr: "invalid address in '[::1]8080': expected [address]:port"},
+		{input: "[localhost]:80", wantErr: `invalid IPv6 address "localhost" in '[localhost]:80'`},
+		{input: "no-such-interface0:8080", wantErr: `unknown address or interface "no-such-interface0"`},
+		{input: "127.0.0.1:99999", wantErr: "port 99999 out of range (1-65535)"},
+	} {
+		got, err := ParseTarget(tt.input)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseTarget(%q) error = %v, want %q", tt.input, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTarget(%q) error = %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.Ports, tt.ports) || got.Protocol != tt.protocol || got.Address != tt.address {
+			t.Errorf("ParseTarget(%q) = %v %q %q, want %v %q %q",
+				tt.input, got.Ports, got.Protocol, got.Address, tt.ports, tt.protocol, tt.address)
+		}
+	}
+}
+
+func TestPortTargetMatch(t *testing.T) {
+	for _, tt := range []struct {
+		target    string
+		port      int
+		protocol  string
+		localAddr string
+		want      bool
+	}{
+		{"8080", 8080, "TCP", "0.0.0.0:8080", true},
+		{"8080", 8081, "TCP", "0.0.0.0:8081", false},
+		{"8080/tcp", 8080, "TCP", "0.0.0.0:8080", true},
+		{"8080/TCP", 8080, "tcp", "0.0.0.0:8080", true},
+		{"8080/tcp", 8080, "tcp6", ":::8080", true},
+		{"8080/tcp", 8080, "UDP", "0.0.0.0:8080", false},
+		{"53/udp", 53, "udp6", "[::]:53", true},
+		{"127.0.0.1:8080", 8080, "TCP", "127.0.0.1:8080", true},
+		{"127.0.0.1:8080", 8080, "TCP", "0.0.0.0:8080", false},
+		{"0.0.0.0:8080", 8080, "TCP", "127.0.0.1:8080", false},
+		{"[::1]:8080", 8080, "TCP", "[::1]:8080", true},
+		{"[::1]:8080", 8080, "TCP", "::1:8080", true},
+		{"[::1]:8080", 8080, "TCP", "127.0.0.1:8080", false},
+		{"[::]:8080", 8080, "TCP", ":::8080", true},
+		// lsof 用 * 表示未指定地址
+		{"0.0.0.0:22", 22, "TCP", "*:22", true},
+		{"[::]:22", 22, "TCP", "*:22", true},
+		{"127.0.0.1:22", 22, "TCP", "*:22", false},
+		{"*:22", 22, "TCP", "*:22", true},
+		{"*:22", 22, "TCP", "0.0.0.0:22", true},
+		{"*:22", 22, "TCP", "[::]:22", true},
+		{"*:22", 22, "TCP", "127.0.0.1:22", false},
+		// 区域标识可在方括号内外，netstat 不加方括号
+		{"[fe80::1%eth0]:546", 546, "UDP", "[fe80::1]%eth0:546", true},
+		{"[fe80::1%eth0]:546", 546, "UDP", "[fe80::1%eth0]:546", true},
+		{"[fe80::1%eth0]:546", 546, "UDP", "fe80::1%eth0:546", true},
+		{"[fe80::1]:546", 546, "UDP", "[fe80::1]%eth0:546", true},
+		{"[fe80::1]:546", 546, "UDP", "[fe80::2]%eth0:546", false},
+	} {
+		target, err := ParseTarget(tt.target)
+		if err != nil {
+			t.Fatalf("ParseTarget(%q) error = %v", tt.target, err)
+		}
+		if got := target.Match(tt.port, tt.protocol, tt.localAddr); got != tt.want {
+			t.Errorf("%q.Match(%d, %q, %q) = %v, want %v", tt.target, tt.port, tt.protocol, tt.localAddr, got, tt.want)
+		}
+	}
+}
+
+func TestPortTargetMatchInterface(t *testing.T) {
+	var loopback *net.Interface
+	if ifaces, err := net.Interfaces(); err == nil {
+		for i := range ifaces {
+			if ifaces[i].Flags&net.FlagLoopback != 0 {
+				loopback = &ifaces[i]
+				break
+			}
+		}
+	}
+	if loopback == nil {
+		t.Skip("no loopback interface")
+	}
+
+	target, err := ParseTarget(loopback.Name + ":53/udp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 绑定到网卡的套接字按区域匹配，其他套接字按网卡的地址匹配
+	for _, tt := range []struct {
+		localAddr string
+		want      bool
+	}{
+		{"127.0.0.53%" + loopback.Name + ":53", true},
+		{"0.0.0.0%" + loopback.Name + ":53", true},
+		{"127.0.0.1:53", true},
+		{"0.0.0.0:53", false},
+		{"127.0.0.1%no-such-interface0:53", true},
+		{"192.0.2.1%no-such-interface0:53", false},
+	} {
+		if got := target.Match(53, "UDP", tt.localAddr); got != tt.want {
+			t.Errorf("%s.Match(53, UDP, %q) = %v, want %v", target, tt.localAddr, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLocalHost(t *testing.T) {
+	for _, tt := range []struct {
+		localAddr string
+		host      string
+		zone      string
+	}{
+		{"0.0.0.0:80", "0.0.0.0", ""},
+		{"127.0.0.1:8080", "127.0.0.1", ""},
+		{"[::]:22", "::", ""},
+		{":::22", "::", ""},
+		{"::1:8080", "::1", ""},
+		{"*:22", "*", ""},
+		{"127.0.0.53%lo:53", "127.0.0.53", "lo"},
+		{"[fe80::1]%eth0:546", "fe80::1", "eth0"},
+		{"[fe80::1%eth0]:546", "fe80::1", "eth0"},
+		{"fe80::1%eth0:546", "fe80::1", "eth0"},
+		{"fe80::1%3:546", "fe80::1", "3"},
+	} {
+		host, zone := splitLocalHost(tt.localAddr)
+		if host != tt.host || zone != tt.zone {
+			t.Errorf("splitLocalHost(%q) = %q, %q, want %q, %q", tt.localAddr, host, zone, tt.host, tt.zone)
+		}
+	}
+}
+
+func TestStripZone(t *testing.T) {
+	for _, tt := range []struct{ host, want string }{
+		{"fe80::1%eth0", "fe80::1"},
+		{"fe80::1%3", "fe80::1"},
+		{"fe80::1%", "fe80::1"},
+		{"::1", "::1"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"", ""},
+	} {
+		if got := stripZone(tt.host); got != tt.want {
+			t.Errorf("stripZone(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
@@ -1,15 +1,16 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"portreleasor/internal/cli"
+	"portreleasor/internal/core"
+	"portreleasor/internal/i18n"
 )
 
 func main() {
 	if err := cli.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		i18n.Fprintf(os.Stderr, "Error: %s\n", i18n.Localize(err))
+		os.Exit(core.ExitCode(err))
 	}
 }
\ No newline at end of file
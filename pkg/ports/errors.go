@@ -0,0 +1,79 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedPlatform is returned when no platform manager exists for the current OS
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+	// ErrNoMatch is returned by Release when the plan matches no process
+	ErrNoMatch = errors.New("no process matches the plan")
+	// ErrEmptyPlan is returned by Release when the plan selects neither ports nor processes
+	ErrEmptyPlan = errors.New("plan selects no ports or processes")
+)
+
+// InvalidInputError reports a port, range, service name or other input that could not
+// be parsed. Input is the offending port, if known.
+type InvalidInputError struct {
+	Input string
+	Err   error
+}
+
+func (e *InvalidInputError) Error() string {
+	if e.Input == "" {
+		return fmt.Sprintf("invalid input: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid port %q: %v", e.Input, e.Err)
+}
+
+func (e *InvalidInputError) Unwrap() error {
+	return e.Err
+}
+
+// CollectError reports that the platform tools (ss, lsof, netstat, ...) could not list
+// the sockets or processes, or did not finish within their timeouts
+type CollectError struct {
+	Err error
+}
+
+func (e *CollectError) Error() string {
+	return fmt.Sprintf("failed to list sockets: %v", e.Err)
+}
+
+func (e *CollectError) Unwrap() error {
+	return e.Err
+}
+
+// ReleaseError reports a release in which some targets were skipped as protected,
+// could not be killed, or whose ports were still in use afterwards. Report holds the
+// outcome of every target, including the ones that succeeded.
+type ReleaseError struct {
+	Report *Report
+}
+
+func (e *ReleaseError) Error() string {
+	var skipped, failed int
+	for _, result := range e.Report.Results {
+		switch {
+		case result.Skipped != "":
+			skipped++
+		case !result.Killed:
+			failed++
+		}
+	}
+
+	var parts []string
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d process(es) could not be killed", failed))
+	}
+	if skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d protected process(es) skipped", skipped))
+	}
+	if len(e.Report.StillInUse) > 0 {
+		parts = append(parts, fmt.Sprintf("%d port(s) still in use", len(e.Report.StillInUse)))
+	}
+	return "release incomplete: " + strings.Join(parts, ", ")
+}
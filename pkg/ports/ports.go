@@ -0,0 +1,205 @@
+// Package ports lists the sockets in use on the local machine and releases them by
+// terminating their owning processes. It is the embeddable counterpart of the
+// portreleasor check and release commands: nothing is printed and failures are
+// returned as the typed errors declared in errors.go.
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"portreleasor/internal/core"
+	"portreleasor/internal/platform"
+	"portreleasor/internal/types"
+	"portreleasor/internal/utils"
+)
+
+// Port is one listening socket and the process that owns it
+type Port = types.PortInfo
+
+// FieldStatus is how one process field of a Port was resolved; Port.Status holds it
+// for every field that was looked up, keyed by the field's JSON name
+type FieldStatus = types.FieldStatus
+
+// Field statuses
+const (
+	StatusOK               = types.StatusOK
+	StatusPermissionDenied = types.StatusPermissionDenied
+	StatusProcessGone      = types.StatusProcessGone
+	StatusUnsupported      = types.StatusUnsupported
+)
+
+// Target is a process that a release terminates, with every port it holds
+type Target = core.ReleaseTarget
+
+// Result is the outcome of terminating one target
+type Result = core.ReleaseResult
+
+// Report is the outcome of a release
+type Report = core.ReleaseReport
+
+// Timeouts bounds how long each platform tool, e.g. "ss" or "lsof", may run
+type Timeouts = platform.Timeouts
+
+// WithTimeouts returns a context under which List and Release bound every platform
+// tool by the timeouts instead of the default of 10 seconds
+func WithTimeouts(ctx context.Context, timeouts Timeouts) context.Context {
+	return platform.WithTimeouts(ctx, timeouts)
+}
+
+// WithReporter returns a context under which List and Release report platform tools
+// that timed out or failed over to a fallback to report, instead of ignoring them
+func WithReporter(ctx context.Context, report func(message string)) context.Context {
+	return platform.WithReporter(ctx, report)
+}
+
+// Invalidate discards the sockets and processes List reuses for up to a second, so the
+// next call rescans the system. Release invalidates them itself after killing.
+func Invalidate() {
+	platform.Invalidate(platform.GetPlatformManager())
+}
+
+// Filter selects the ports returned by List. Empty fields match everything; when both
+// ports and processes are given a port must match both.
+type Filter struct {
+	// Ports are port numbers, ranges or service names, optionally qualified by protocol
+	// and local address or interface, e.g. "8080", "8000-8100", "https", "53/udp",
+	// "127.0.0.1:8080", "[::1]:9000" or "docker0:5432"
+	Ports []string
+	// Processes are process names, matched case-insensitively without the .exe suffix
+	Processes []string
+	// PIDs are process IDs
+	PIDs []int
+	// Exes are executable paths
+	Exes []string
+	// Details fills in the command line, working directory, start time, user and container
+	Details bool
+}
+
+// Plan selects the processes Release terminates: the owners of Ports, given as in
+// Filter, plus the processes matching Processes, PIDs or Exes
+type Plan struct {
+	Ports     []string
+	Processes []string
+	PIDs      []int
+	Exes      []string
+	// DryRun returns the targets without killing anything
+	DryRun bool
+}
+
+// List returns the ports in use that match the filter, sorted by port, protocol and address
+func List(ctx context.Context, filter Filter) ([]Port, error) {
+	targets, err := parseTargets(filter.Ports)
+	if err != nil {
+		return nil, err
+	}
+	if platform.GetPlatformManager() == nil {
+		return nil, ErrUnsupportedPlatform
+	}
+
+	// 先筛选再解析进程详情，只查询匹配端口的进程
+	opts := core.CheckOptions{
+		Targets:   targets,
+		Processes: filter.Processes,
+		PIDs:      filter.PIDs,
+		Exes:      filter.Exes,
+	}
+
+	var matched []Port
+	if err := run(ctx, func(ctx context.Context) (err error) {
+		matched, err = core.QueryPorts(ctx, nil, opts, filter.Details)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if matched == nil {
+		matched = []Port{}
+	}
+
+	return matched, nil
+}
+
+// Release terminates the processes selected by the plan and verifies that their ports
+// were freed. Protected system processes are never killed. A *ReleaseError is returned,
+// together with the report, if any target was skipped, failed, or left a port in use.
+func Release(ctx context.Context, plan Plan) (*Report, error) {
+	filter := core.ProcessFilter{Names: plan.Processes, PIDs: plan.PIDs, Exes: plan.Exes}
+	if len(plan.Ports) == 0 && filter.IsEmpty() {
+		return nil, ErrEmptyPlan
+	}
+	if _, err := parseTargets(plan.Ports); err != nil {
+		return nil, err
+	}
+	if platform.GetPlatformManager() == nil {
+		return nil, ErrUnsupportedPlatform
+	}
+
+	var targets []Target
+	if err := run(ctx, func(ctx context.Context) (err error) {
+		targets, err = core.PlanRelease(ctx, plan.Ports, filter)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoMatch
+	}
+
+	if plan.DryRun {
+		return &Report{DryRun: true, Targets: targets}, nil
+	}
+
+	// 已经开始终止进程后不再响应取消，保证报告完整
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	report := core.ExecuteRelease(context.WithoutCancel(ctx), targets)
+
+	for _, result := range report.Results {
+		if !result.Killed {
+			return &report, &ReleaseError{Report: &report}
+		}
+	}
+	if len(report.StillInUse) > 0 {
+		return &report, &ReleaseError{Report: &report}
+	}
+	return &report, nil
+}
+
+// parseTargets parses port numbers, ranges and service names with their qualifiers
+func parseTargets(inputs []string) ([]utils.PortTarget, error) {
+	var targets []utils.PortTarget
+	for _, input := range inputs {
+		target, err := utils.ParseTarget(input)
+		if err != nil {
+			return nil, &InvalidInputError{Input: input, Err: err}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// run calls fn, returning the context's error if it is cancelled or times out first;
+// the platform tools are stopped as soon as ctx is done. Failures are returned as the
+// error type of their kind.
+func run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := fn(ctx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, core.ErrInvalidPattern):
+		return &InvalidInputError{Err: err}
+	case errors.Is(err, core.ErrNoMatch):
+		return ErrNoMatch
+	case errors.Is(err, core.ErrBackendUnavailable), errors.Is(err, core.ErrTimeout):
+		return &CollectError{Err: err}
+	}
+	return err
+}
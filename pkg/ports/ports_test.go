@@ -0,0 +1,248 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"portreleasor/internal/platform"
+	"portreleasor/internal/platform/platformtest"
+	"portreleasor/internal/types"
+)
+
+// installFake serves the captured ss listing of a Linux host for the duration of the test
+func installFake(t *testing.T) *platformtest.FakeManager {
+	t.Helper()
+
+	output, err := os.ReadFile("../../internal/platform/testdata/ss_tunap.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &platformtest.FakeManager{
+		Replay: platformtest.Replay{Format: platformtest.FormatSS, Output: string(output)},
+		Processes: []types.ProcessInfo{
+			{PID: 1, Name: "systemd"},
+			{PID: 612, PPID: 1, Name: "systemd-resolve"},
+			{PID: 1001, PPID: 1, Name: "sshd"},
+			{PID: 1200, PPID: 1, Name: "nginx"},
+			{PID: 1201, PPID: 1200, Name: "nginx"},
+			{PID: 4242, PPID: 1, Name: "python3"},
+		},
+		Paths: map[int]string{4242: "/usr/bin/python3"},
+		Users: map[int]string{4242: "alice", 1200: "root", 1201: "www-data"},
+	}
+	t.Cleanup(fake.Install())
+	t.Cleanup(Invalidate)
+	return fake
+}
+
+func TestList(t *testing.T) {
+	installFake(t)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int
+	}{
+		{"everything", Filter{}, []int{22, 53, 80, 2049, 2049, 8080}},
+		{"port", Filter{Ports: []string{"8080"}}, []int{8080}},
+		{"range", Filter{Ports: []string{"8000-8100"}}, []int{8080}},
+		{"service", Filter{Ports: []string{"ssh", "http"}}, []int{22, 22, 80, 80}},
+		{"protocol", Filter{Ports: []string{"2049/udp"}}, []int{2049}},
+		{"address", Filter{Ports: []string{"127.0.0.53:53"}}, []int{53}},
+		{"process", Filter{Processes: []string{"NGINX"}}, []int{80, 80}},
+		{"worker", Filter{PIDs: []int{1201}}, []int{80}},
+		{"pid", Filter{PIDs: []int{4242}}, []int{8080}},
+		{"exe", Filter{Exes: []string{"/usr/bin/python3"}}, []int{8080}},
+		{"port and process", Filter{Ports: []string{"22", "8080"}, Processes: []string{"sshd"}}, []int{22, 22}},
+		{"no match", Filter{Ports: []string{"9"}}, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, err := List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []int{}
+			for _, port := range ports {
+				got = append(got, port.Port)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List(%+v) returned ports %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListAddresses(t *testing.T) {
+	// sshd 同时监听 0.0.0.0:22 和 [::]:22
+	installFake(t)
+
+	tests := []struct {
+		ports []string
+		want  []string
+	}{
+		{[]string{"22"}, []string{"0.0.0.0:22", "[::]:22"}},
+		{[]string{"[::]:22"}, []string{"[::]:22"}},
+		{[]string{"0.0.0.0:22/tcp"}, []string{"0.0.0.0:22"}},
+		{[]string{"127.0.0.1:22"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		ports, err := List(context.Background(), Filter{Ports: tt.ports})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, port := range ports {
+			got = append(got, port.LocalAddr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("List(%v) returned addresses %v, want %v", tt.ports, got, tt.want)
+		}
+	}
+}
+
+func TestListMatchesRelease(t *testing.T) {
+	installFake(t)
+
+	// List 与 Release 对同一筛选条件必须选中相同的进程
+	for _, filter := range []Filter{
+		{PIDs: []int{1201}},
+		{Processes: []string{"nginx"}},
+		{Ports: []string{"80"}},
+		{Ports: []string{"[::]:22"}},
+	} {
+		ports, err := List(context.Background(), filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed := map[int]bool{}
+		for _, port := range ports {
+			listed[port.PID] = true
+		}
+
+		report, err := Release(context.Background(), Plan{Ports: filter.Ports, Processes: filter.Processes, PIDs: filter.PIDs, DryRun: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		planned := map[int]bool{}
+		for _, target := range report.Targets {
+			planned[target.PID] = true
+		}
+
+		if !reflect.DeepEqual(listed, planned) {
+			t.Errorf("%+v: List selected PIDs %v, Release %v", filter, listed, planned)
+		}
+	}
+}
+
+// userCounter records the processes whose user is looked up
+type userCounter struct {
+	*platformtest.FakeManager
+	mu   sync.Mutex
+	pids []int
+}
+
+func (c *userCounter) GetProcessUser(ctx context.Context, pid int) (string, error) {
+	c.mu.Lock()
+	c.pids = append(c.pids, pid)
+	c.mu.Unlock()
+	return c.FakeManager.GetProcessUser(ctx, pid)
+}
+
+func TestListDetailsOnlyMatching(t *testing.T) {
+	counter := &userCounter{FakeManager: installFake(t)}
+	platform.GetPlatformManager = func() platform.PlatformManager { return counter }
+
+	ports, err := List(context.Background(), Filter{Ports: []string{"8080"}, Details: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 1 || ports[0].User != "alice" || ports[0].ProcessPath != "/usr/bin/python3" {
+		t.Fatalf("List returned %+v, want python3 owned by alice", ports)
+	}
+	if !reflect.DeepEqual(counter.pids, []int{4242}) {
+		t.Errorf("looked up the users of %v, want only 4242", counter.pids)
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	fake := installFake(t)
+
+	var inputErr *InvalidInputError
+	if _, err := List(context.Background(), Filter{Ports: []string{"99999"}}); !errors.As(err, &inputErr) || inputErr.Input != "99999" {
+		t.Errorf("List with an invalid port returned %v, want an *InvalidInputError", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := List(ctx, Filter{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("List with a cancelled context returned %v, want context.Canceled", err)
+	}
+
+	fake.Format = "unknown"
+	var collectErr *CollectError
+	if _, err := List(context.Background(), Filter{}); !errors.As(err, &collectErr) {
+		t.Errorf("List with a broken backend returned %v, want a *CollectError", err)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	fake := installFake(t)
+
+	report, err := Release(context.Background(), Plan{Ports: []string{"8080"}, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || len(report.Targets) != 1 || report.Targets[0].PID != 4242 {
+		t.Errorf("dry run report = %+v, want PID 4242", report)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Fatalf("dry run killed %v", got)
+	}
+
+	report, err = Release(context.Background(), Plan{Ports: []string{"8080"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 1 || !report.Results[0].Killed || len(report.StillInUse) != 0 {
+		t.Errorf("report = %+v, want PID 4242 killed", report)
+	}
+	if got := fake.Killed(); !reflect.DeepEqual(got, []int{4242}) {
+		t.Errorf("killed %v, want [4242]", got)
+	}
+}
+
+func TestReleaseErrors(t *testing.T) {
+	fake := installFake(t)
+	fake.KillErrors = map[int]error{1001: errors.New("operation not permitted")}
+
+	if _, err := Release(context.Background(), Plan{}); !errors.Is(err, ErrEmptyPlan) {
+		t.Errorf("empty plan returned %v, want ErrEmptyPlan", err)
+	}
+	if _, err := Release(context.Background(), Plan{Ports: []string{"9"}}); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("unused port returned %v, want ErrNoMatch", err)
+	}
+	var inputErr *InvalidInputError
+	if _, err := Release(context.Background(), Plan{Ports: []string{"http/sctp"}}); !errors.As(err, &inputErr) {
+		t.Errorf("invalid port returned %v, want an *InvalidInputError", err)
+	}
+
+	report, err := Release(context.Background(), Plan{PIDs: []int{1001}})
+	var releaseErr *ReleaseError
+	if !errors.As(err, &releaseErr) || releaseErr.Report != report {
+		t.Fatalf("failed kill returned %v, want a *ReleaseError with the report", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Killed || report.Results[0].Error == "" {
+		t.Errorf("report = %+v, want the failed kill of PID 1001", report)
+	}
+	if got := fake.Killed(); len(got) != 0 {
+		t.Errorf("killed %v, want nothing", got)
+	}
+}